@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"event-journal-backend/config"
+	"event-journal-backend/i18n"
 	"event-journal-backend/services"
 
 	"github.com/gin-gonic/gin"
@@ -18,21 +19,28 @@ import (
 // ===== HELPER =====
 //
 
-func getEventData(ctx context.Context, eventID int) (string, string, string, int, error) {
-	var title, email, fcmToken string
-	var userID int
+type eventCreator struct {
+	Title    string
+	Email    string
+	FCMToken string
+	UserID   int
+	Locale   string
+}
+
+func getEventData(ctx context.Context, eventID int) (eventCreator, error) {
+	var data eventCreator
 
 	query := `
-	SELECT e.title, u.email, u.fcm_token, u.id
+	SELECT e.title, u.email, COALESCE(u.fcm_token, ''), u.id, COALESCE(u.locale, '')
 	FROM event_journal.events e
 	JOIN event_journal.users u ON u.id = e.created_by
 	WHERE e.id = $1
 	`
 
 	err := config.DB.QueryRow(ctx, query, eventID).
-		Scan(&title, &email, &fcmToken, &userID)
+		Scan(&data.Title, &data.Email, &data.FCMToken, &data.UserID, &data.Locale)
 
-	return title, email, fcmToken, userID, err
+	return data, err
 }
 
 //
@@ -41,7 +49,7 @@ func getEventData(ctx context.Context, eventID int) (string, string, string, int
 
 func GetPendingEvents(c *gin.Context) {
 	if c.GetString("role") != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "admin only"})
+		c.JSON(http.StatusForbidden, gin.H{"error": tr(c, "admin only")})
 		return
 	}
 
@@ -51,8 +59,7 @@ func GetPendingEvents(c *gin.Context) {
 		e.title,
 		e.event_date,
 		e.location_name,
-		u.id,
-		u.email,
+		` + profileSummaryColumns("u") + `,
 		e.created_at
 	FROM event_journal.events e
 	JOIN event_journal.users u ON u.id = e.created_by
@@ -75,8 +82,7 @@ func GetPendingEvents(c *gin.Context) {
 			title        string
 			eventDate    sql.NullTime
 			locationName sql.NullString
-			userID       sql.NullInt32
-			email        sql.NullString
+			creator      profileSummary
 			createdAt    time.Time
 		)
 
@@ -85,8 +91,10 @@ func GetPendingEvents(c *gin.Context) {
 			&title,
 			&eventDate,
 			&locationName,
-			&userID,
-			&email,
+			&creator.ID,
+			&creator.Username,
+			&creator.DisplayName,
+			&creator.AvatarURL,
 			&createdAt,
 		); err != nil {
 			continue
@@ -98,10 +106,7 @@ func GetPendingEvents(c *gin.Context) {
 			"event_date": eventDate,
 			"location":   locationName,
 			"created_at": createdAt,
-			"creator": gin.H{
-				"id":    userID,
-				"email": email,
-			},
+			"creator":    creator,
 		})
 	}
 
@@ -115,7 +120,7 @@ func GetPendingEvents(c *gin.Context) {
 func ApproveEvent(c *gin.Context) {
 	eventID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event id"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid event id")})
 		return
 	}
 
@@ -131,31 +136,33 @@ func ApproveEvent(c *gin.Context) {
 
 	result, err := config.DB.Exec(context.Background(), query, eventID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to approve event"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to approve event")})
 		return
 	}
 
 	rows := result.RowsAffected()
 	if rows == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "event not found or already processed"})
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "event not found or already processed")})
 		return
 	}
 
 	CreateModerationLog(context.Background(), eventID, adminID, "approved", nil)
 
 	// Get event data (creator info)
-	title, email, fcmToken, userID, err := getEventData(context.Background(), eventID)
+	creator, err := getEventData(context.Background(), eventID)
 	if err == nil {
+		pushTitle := i18n.T(creator.Locale, "push.event_approved.title")
+		pushBody := i18n.T(creator.Locale, "push.event_approved.body", creator.Title)
 
 		// Email
-		go services.SendEventApproved(email, title, eventID)
+		go services.SendEventApproved(creator.Email, creator.Locale, creator.Title, eventID)
 
 		// Push to creator
-		if fcmToken != "" {
+		if creator.FCMToken != "" {
 			go services.SendPushToToken(
-				fcmToken,
-				"Event Approved 🎉",
-				"Your event '"+title+"' has been approved!",
+				creator.FCMToken,
+				pushTitle,
+				pushBody,
 				map[string]string{
 					"type":     "event_approved",
 					"event_id": strconv.Itoa(eventID),
@@ -164,22 +171,11 @@ func ApproveEvent(c *gin.Context) {
 		}
 
 		// Save notification history
-		go services.SaveNotification(
-			userID,
-			"Event Approved 🎉",
-			"Your event '"+title+"' has been approved!",
-		)
+		go services.SaveNotification(creator.UserID, pushTitle, pushBody)
 	}
 
-	// Broadcast to all users
-	go services.BroadcastToAllUsers(
-		"New Event Available 🎊",
-		title,
-		map[string]string{
-			"type":     "new_event",
-			"event_id": strconv.Itoa(eventID),
-		},
-	)
+	// Push to users around the event location
+	go services.NotifyUsersNearEvent(eventID)
 
 	c.JSON(http.StatusOK, gin.H{"message": "event approved"})
 }
@@ -195,7 +191,7 @@ type RejectEventInput struct {
 func RejectEvent(c *gin.Context) {
 	eventID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event id"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid event id")})
 		return
 	}
 
@@ -203,7 +199,7 @@ func RejectEvent(c *gin.Context) {
 
 	var input RejectEventInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "rejection reason required"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "rejection reason required")})
 		return
 	}
 
@@ -222,13 +218,13 @@ func RejectEvent(c *gin.Context) {
 		input.Reason,
 	)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reject event"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to reject event")})
 		return
 	}
 
 	rows := result.RowsAffected()
 	if rows == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "event not found or already processed"})
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "event not found or already processed")})
 		return
 	}
 
@@ -240,16 +236,18 @@ func RejectEvent(c *gin.Context) {
 		&input.Reason,
 	)
 
-	title, email, fcmToken, userID, err := getEventData(context.Background(), eventID)
+	creator, err := getEventData(context.Background(), eventID)
 	if err == nil {
+		pushTitle := i18n.T(creator.Locale, "push.event_rejected.title")
+		pushBody := i18n.T(creator.Locale, "push.event_rejected.body", creator.Title)
 
-		go services.SendEventRejected(email, title, input.Reason, eventID)
+		go services.SendEventRejected(creator.Email, creator.Locale, creator.Title, input.Reason, eventID)
 
-		if fcmToken != "" {
+		if creator.FCMToken != "" {
 			go services.SendPushToToken(
-				fcmToken,
-				"Event Rejected ❌",
-				"Your event '"+title+"' was rejected.",
+				creator.FCMToken,
+				pushTitle,
+				pushBody,
 				map[string]string{
 					"type":     "event_rejected",
 					"event_id": strconv.Itoa(eventID),
@@ -257,11 +255,7 @@ func RejectEvent(c *gin.Context) {
 			)
 		}
 
-		go services.SaveNotification(
-			userID,
-			"Event Rejected ❌",
-			"Your event '"+title+"' was rejected.",
-		)
+		go services.SaveNotification(creator.UserID, pushTitle, pushBody)
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "event rejected"})
@@ -307,7 +301,7 @@ func GetEventModerationLogs(c *gin.Context) {
 		l.action,
 		l.reason,
 		l.created_at,
-		u.email
+		` + profileSummaryColumns("u") + `
 	FROM event_journal.event_moderation_logs l
 	JOIN event_journal.users u ON u.id = l.admin_id
 	WHERE l.event_id = $1
@@ -316,7 +310,7 @@ func GetEventModerationLogs(c *gin.Context) {
 
 	rows, err := config.DB.Query(context.Background(), query, eventID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch logs"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch logs")})
 		return
 	}
 	defer rows.Close()
@@ -327,13 +321,21 @@ func GetEventModerationLogs(c *gin.Context) {
 		var action string
 		var reason sql.NullString
 		var createdAt time.Time
-		var adminEmail string
+		var admin profileSummary
 
-		rows.Scan(&action, &reason, &createdAt, &adminEmail)
+		rows.Scan(
+			&action,
+			&reason,
+			&createdAt,
+			&admin.ID,
+			&admin.Username,
+			&admin.DisplayName,
+			&admin.AvatarURL,
+		)
 
 		logItem := gin.H{
 			"action": action,
-			"admin":  adminEmail,
+			"admin":  admin,
 			"time":   createdAt,
 		}
 
@@ -3,9 +3,11 @@ package controllers
 import (
 	"context"
 	"net/http"
+	"strings"
 	"time"
 
 	"event-journal-backend/config"
+	"event-journal-backend/i18n"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -20,6 +22,8 @@ type RegisterInput struct {
 	Name     string `json:"name"`
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	Locale   string `json:"locale"`
+	Username string `json:"username"`
 }
 
 type LoginInput struct {
@@ -37,6 +41,31 @@ func Register(c *gin.Context) {
 		return
 	}
 
+	// tanpa pilihan eksplisit locale tetap NULL, jadi Accept-Language
+	// tiap request yang menentukan bahasanya
+	var locale *string
+	if input.Locale != "" {
+		if !i18n.IsSupported(input.Locale) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":     tr(c, "unsupported locale"),
+				"supported": i18n.Supported,
+			})
+			return
+		}
+		locale = &input.Locale
+	}
+
+	// username opsional; kalau kosong dibuat otomatis "user<id>"
+	var username *string
+	if input.Username != "" {
+		normalized := strings.ToLower(strings.TrimSpace(input.Username))
+		if !validUsername(normalized) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "username must be 3-30 characters of a-z, 0-9, _ or .")})
+			return
+		}
+		username = &normalized
+	}
+
 	hashedPassword, _ := bcrypt.GenerateFromPassword(
 		[]byte(input.Password),
 		bcrypt.DefaultCost,
@@ -46,8 +75,12 @@ func Register(c *gin.Context) {
 	defer cancel()
 
 	query := `
-		INSERT INTO users (name, email, password)
-		VALUES ($1, $2, $3)
+		WITH next AS (
+			SELECT nextval(pg_get_serial_sequence('event_journal.users', 'id')) AS id
+		)
+		INSERT INTO users (id, name, email, password, locale, username)
+		SELECT id, $1, $2, $3, $4, COALESCE($5, 'user' || id)
+		FROM next
 	`
 
 	_, err := config.DB.Exec(
@@ -56,11 +89,18 @@ func Register(c *gin.Context) {
 		input.Name,
 		input.Email,
 		string(hashedPassword),
+		locale,
+		username,
 	)
 
+	if isUniqueViolationOn(err, "username") {
+		c.JSON(http.StatusConflict, gin.H{"error": tr(c, "username already taken")})
+		return
+	}
+
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "email already exists",
+			"error": tr(c, "email already exists"),
 		})
 		return
 	}
@@ -98,7 +138,7 @@ func Login(c *gin.Context) {
 
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "invalid email or password",
+			"error": tr(c, "invalid email or password"),
 		})
 		return
 	}
@@ -108,7 +148,7 @@ func Login(c *gin.Context) {
 		[]byte(input.Password),
 	); err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "invalid email or password",
+			"error": tr(c, "invalid email or password"),
 		})
 		return
 	}
@@ -124,7 +164,7 @@ func Login(c *gin.Context) {
 	signedToken, err := token.SignedString(jwtSecret)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "failed to generate token",
+			"error": tr(c, "failed to generate token"),
 		})
 		return
 	}
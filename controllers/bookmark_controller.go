@@ -3,15 +3,43 @@ package controllers
 import (
 	"context"
 	"net/http"
+	"strconv"
 	"time"
+	"unicode/utf8"
 
 	"event-journal-backend/config"
+	"event-journal-backend/services"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 )
 
+const maxBookmarkNoteLength = 1000
+
 type BookmarkInput struct {
-	JournalID int `json:"journal_id"`
+	JournalID    int     `json:"journal_id"`
+	CollectionID *int    `json:"collection_id"`
+	Note         *string `json:"note"`
+}
+
+// UpdateBookmarkInput moves a bookmark or edits its note. collection_id 0
+// takes the bookmark out of its collection.
+type UpdateBookmarkInput struct {
+	CollectionID *int    `json:"collection_id"`
+	Note         *string `json:"note"`
+}
+
+// ownsCollection reports whether collectionID belongs to userID.
+func ownsCollection(ctx context.Context, userID, collectionID int) bool {
+	var exists bool
+	err := config.DB.QueryRow(
+		ctx,
+		`SELECT EXISTS (SELECT 1 FROM bookmark_collections WHERE id = $1 AND user_id = $2)`,
+		collectionID,
+		userID,
+	).Scan(&exists)
+
+	return err == nil && exists
 }
 
 func BookmarkJournal(c *gin.Context) {
@@ -22,69 +50,217 @@ func BookmarkJournal(c *gin.Context) {
 		return
 	}
 
-	userID, _ := c.Get("user_id")
+	if input.Note != nil && utf8.RuneCountInString(*input.Note) > maxBookmarkNoteLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "note is too long")})
+		return
+	}
+
+	userID := c.GetInt("user_id")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := `
-		INSERT INTO bookmarks (user_id, journal_id)
-		VALUES ($1, $2)
-		ON CONFLICT DO NOTHING
-	`
+	journal, ok := findVisibleJournal(ctx, c, strconv.Itoa(input.JournalID))
+	if !ok {
+		return
+	}
 
-	_, err := config.DB.Exec(ctx, query, userID, input.JournalID)
+	if input.CollectionID != nil && !ownsCollection(ctx, userID, *input.CollectionID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "collection not found")})
+		return
+	}
+
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to bookmark")})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	added, err := saveBookmark(ctx, tx, userID, journal.ID, input.CollectionID, input.Note)
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to bookmark"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to bookmark")})
 		return
 	}
 
+	if added {
+		go services.BumpJournalPopularity(journal.ID, services.PopularityBookmark)
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "journal bookmarked",
 	})
 }
 
-func UnbookmarkJournal(c *gin.Context) {
+// saveBookmark bookmarks journalID for userID inside tx. Bookmarking again
+// only updates the collection or note that were sent. It reports whether
+// the bookmark is new.
+func saveBookmark(ctx context.Context, tx pgx.Tx, userID, journalID int, collectionID *int, note *string) (bool, error) {
+	query := `
+		INSERT INTO bookmarks (user_id, journal_id, collection_id, note)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, journal_id) DO UPDATE SET
+			collection_id = COALESCE(EXCLUDED.collection_id, bookmarks.collection_id),
+			note = COALESCE(EXCLUDED.note, bookmarks.note),
+			updated_at = NOW()
+		RETURNING (xmax = 0)
+	`
+
+	var added bool
+	err := tx.QueryRow(ctx, query, userID, journalID, collectionID, note).Scan(&added)
+	if err == nil && added {
+		err = setSyncTombstone(ctx, tx, userID, tombstoneBookmark, journalID, false)
+	}
+	if err == nil && added {
+		_, err = adjustJournalCounter(ctx, tx, journalID, "bookmark_count", 1)
+	}
+
+	return added, err
+}
+
+// removeBookmark deletes the bookmark inside tx and reports whether there
+// was one.
+func removeBookmark(ctx context.Context, tx pgx.Tx, userID, journalID int) (bool, error) {
+	query := `
+		DELETE FROM bookmarks
+		WHERE user_id = $1 AND journal_id = $2
+	`
+
+	result, err := tx.Exec(ctx, query, userID, journalID)
+	removed := err == nil && result.RowsAffected() > 0
+	if removed {
+		err = setSyncTombstone(ctx, tx, userID, tombstoneBookmark, journalID, true)
+	}
+	if removed && err == nil {
+		_, err = adjustJournalCounter(ctx, tx, journalID, "bookmark_count", -1)
+	}
+
+	return removed, err
+}
+
+func UpdateBookmark(c *gin.Context) {
 	journalID := c.Param("journal_id")
-	userID, _ := c.Get("user_id")
+	userID := c.GetInt("user_id")
+
+	var input UpdateBookmarkInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Note != nil && utf8.RuneCountInString(*input.Note) > maxBookmarkNoteLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "note is too long")})
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	moveTo := 0
+	if input.CollectionID != nil {
+		moveTo = *input.CollectionID
+		if moveTo != 0 && !ownsCollection(ctx, userID, moveTo) {
+			c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "collection not found")})
+			return
+		}
+	}
+
 	query := `
-		DELETE FROM bookmarks
+		UPDATE bookmarks SET
+			collection_id = CASE WHEN $3 THEN NULLIF($4, 0) ELSE collection_id END,
+			note = COALESCE($5, note),
+			updated_at = NOW()
 		WHERE user_id = $1 AND journal_id = $2
+		RETURNING collection_id, note
 	`
 
-	_, err := config.DB.Exec(ctx, query, userID, journalID)
+	var collectionID *int
+	var note *string
+
+	err := config.DB.QueryRow(
+		ctx,
+		query,
+		userID,
+		journalID,
+		input.CollectionID != nil,
+		moveTo,
+		input.Note,
+	).Scan(&collectionID, &note)
+
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to unbookmark"})
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "bookmark not found")})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "bookmark removed",
+		"journal_id":    journalID,
+		"collection_id": collectionID,
+		"note":          note,
 	})
 }
 
-func GetMyBookmarks(c *gin.Context) {
-	userID, _ := c.Get("user_id")
+func UnbookmarkJournal(c *gin.Context) {
+	journalID, _ := strconv.Atoi(c.Param("journal_id"))
+	userID := c.GetInt("user_id")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to unbookmark")})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	removed, err := removeBookmark(ctx, tx, userID, journalID)
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to unbookmark")})
+		return
+	}
+
+	if removed {
+		go services.BumpJournalPopularity(journalID, -services.PopularityBookmark)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "bookmark removed",
+	})
+}
+
+// bookmarkFilter selects which of ownerID's bookmarks to list. Journals the
+// viewer can no longer see are always left out; notes are only shown to
+// the owner.
+type bookmarkFilter struct {
+	OwnerID       int
+	ViewerID      int
+	CollectionID  *int
+	Uncategorized bool
+}
+
+func listBookmarkedJournals(ctx context.Context, filter bookmarkFilter) ([]gin.H, error) {
 	query := `
-		SELECT j.id, j.title, j.content, j.latitude, j.longitude, j.created_at
+		SELECT j.id, j.title, j.content, j.latitude, j.longitude, j.created_at,
+		       b.collection_id, b.note, b.created_at,
+		       ` + engagementColumns("j", "$3") + `
 		FROM bookmarks b
 		JOIN journals j ON j.id = b.journal_id
 		WHERE b.user_id = $1
+		  AND ($2::int IS NULL OR b.collection_id = $2)
+		  AND (NOT $4 OR b.collection_id IS NULL)
+		  AND ` + journalListedSQL("j", "$3") + `
 		ORDER BY b.created_at DESC
 	`
 
-	rows, err := config.DB.Query(ctx, query, userID)
+	rows, err := config.DB.Query(ctx, query, filter.OwnerID, filter.CollectionID, filter.ViewerID, filter.Uncategorized)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch bookmarks"})
-		return
+		return nil, err
 	}
 	defer rows.Close()
 
@@ -93,22 +269,65 @@ func GetMyBookmarks(c *gin.Context) {
 	for rows.Next() {
 		var id int
 		var title, content string
-		var lat, lng float64
-		var createdAt time.Time
+		var lat, lng *float64
+		var createdAt, bookmarkedAt time.Time
+		var collectionID *int
+		var note *string
+		var engagement journalEngagement
 
-		if err := rows.Scan(&id, &title, &content, &lat, &lng, &createdAt); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read bookmark"})
-			return
+		if err := rows.Scan(append(
+			[]any{&id, &title, &content, &lat, &lng, &createdAt, &collectionID, &note, &bookmarkedAt},
+			engagement.scanTargets()...,
+		)...); err != nil {
+			return nil, err
 		}
 
-		journals = append(journals, gin.H{
-			"id":         id,
-			"title":      title,
-			"content":    content,
-			"latitude":   lat,
-			"longitude":  lng,
-			"created_at": createdAt,
+		journal := engagement.into(gin.H{
+			"id":            id,
+			"title":         title,
+			"content":       content,
+			"latitude":      lat,
+			"longitude":     lng,
+			"created_at":    createdAt,
+			"collection_id": collectionID,
+			"bookmarked_at": bookmarkedAt,
 		})
+		if filter.ViewerID == filter.OwnerID {
+			journal["note"] = note
+		}
+
+		journals = append(journals, journal)
+	}
+
+	return journals, rows.Err()
+}
+
+// GetMyBookmarks lists the user's bookmarks, optionally narrowed with
+// ?collection_id=<id> or ?collection_id=none for uncategorized ones.
+func GetMyBookmarks(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	filter := bookmarkFilter{OwnerID: userID, ViewerID: userID}
+
+	switch param := c.Query("collection_id"); param {
+	case "":
+	case "none":
+		filter.Uncategorized = true
+	default:
+		id, err := strconv.Atoi(param)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid collection_id")})
+			return
+		}
+		filter.CollectionID = &id
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	journals, err := listBookmarkedJournals(ctx, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch bookmarks")})
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
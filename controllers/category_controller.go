@@ -0,0 +1,294 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"event-journal-backend/config"
+	"event-journal-backend/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+var errUnknownCategory = errors.New("unknown category")
+
+// eventCategoriesSQL selects the category slugs of the event under alias
+// as a text[] column.
+func eventCategoriesSQL(alias string) string {
+	return "ARRAY(SELECT cat.slug FROM event_categories ec JOIN categories cat ON cat.id = ec.category_id" +
+		" WHERE ec.event_id = " + alias + ".id ORDER BY cat.slug)"
+}
+
+// eventInCategorySQL is a WHERE condition that passes every event when the
+// category in param is empty, and otherwise only events in it.
+func eventInCategorySQL(alias, param string) string {
+	return "(" + param + " = '' OR EXISTS (SELECT 1 FROM event_categories fc JOIN categories fcat ON fcat.id = fc.category_id" +
+		" WHERE fc.event_id = " + alias + ".id AND fcat.slug = " + param + "))"
+}
+
+// categoryQuery reads an optional ?category= filter. A malformed category
+// is passed through as is so it simply matches nothing.
+func categoryQuery(c *gin.Context) string {
+	category := c.Query("category")
+	if slug, err := services.NormalizeTag(category); err == nil {
+		return slug
+	}
+	return category
+}
+
+// setEventCategories replaces the categories of an event. Categories are
+// curated, so an unknown slug fails with errUnknownCategory.
+func setEventCategories(ctx context.Context, tx pgx.Tx, eventID int, slugs []string) error {
+	var known int
+	err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM categories WHERE slug = ANY($1)`, slugs).Scan(&known)
+	if err != nil {
+		return err
+	}
+	if known != len(slugs) {
+		return errUnknownCategory
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM event_categories WHERE event_id = $1`, eventID)
+	if err == nil {
+		_, err = tx.Exec(
+			ctx,
+			`INSERT INTO event_categories (event_id, category_id) SELECT $1, id FROM categories WHERE slug = ANY($2)`,
+			eventID,
+			slugs,
+		)
+	}
+	return err
+}
+
+// normalizeCategoriesInput normalizes and dedupes category slugs from a
+// request body, writing the 400 response itself when one is malformed.
+func normalizeCategoriesInput(c *gin.Context, categories []string) ([]string, bool) {
+	seen := map[string]bool{}
+	slugs := []string{}
+
+	for _, category := range categories {
+		slug, err := services.NormalizeTag(category)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "unknown category")})
+			return nil, false
+		}
+		if !seen[slug] {
+			seen[slug] = true
+			slugs = append(slugs, slug)
+		}
+	}
+
+	return slugs, true
+}
+
+//
+// ===== CATEGORIES (PUBLIC) =====
+//
+
+func GetCategories(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT cat.id, cat.slug, cat.name,
+		       (SELECT COUNT(*) FROM event_categories ec
+		        JOIN events e ON e.id = ec.event_id
+		        WHERE ec.category_id = cat.id AND e.status = 'approved')
+		FROM categories cat
+		ORDER BY cat.name ASC
+	`
+
+	rows, err := config.DB.Query(ctx, query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch categories")})
+		return
+	}
+	defer rows.Close()
+
+	categories := []gin.H{}
+
+	for rows.Next() {
+		var id, eventCount int
+		var slug, name string
+
+		if err := rows.Scan(&id, &slug, &name, &eventCount); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch categories")})
+			return
+		}
+
+		categories = append(categories, gin.H{
+			"id":          id,
+			"slug":        slug,
+			"name":        name,
+			"event_count": eventCount,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": categories,
+	})
+}
+
+//
+// ===== CATEGORIES (ADMIN) =====
+//
+
+type CategoryInput struct {
+	Slug *string `json:"slug"`
+	Name *string `json:"name"`
+}
+
+func CreateCategory(c *gin.Context) {
+	var input CategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil || input.Name == nil || strings.TrimSpace(*input.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "category name is required")})
+		return
+	}
+
+	name := strings.TrimSpace(*input.Name)
+
+	// slug default dari nama
+	source := name
+	if input.Slug != nil {
+		source = *input.Slug
+	}
+	slug, err := services.NormalizeTag(source)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid category slug")})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var id int
+	err = config.DB.QueryRow(
+		ctx,
+		`INSERT INTO categories (slug, name) VALUES ($1, $2) RETURNING id`,
+		slug,
+		name,
+	).Scan(&id)
+
+	if isUniqueViolation(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": tr(c, "category already exists")})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to create category")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"id":   id,
+		"slug": slug,
+		"name": name,
+	})
+}
+
+// UpdateCategory renames a category. The slug is kept so existing links
+// and filters keep working.
+func UpdateCategory(c *gin.Context) {
+	var input CategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil || input.Name == nil || strings.TrimSpace(*input.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "category name is required")})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var slug string
+	err := config.DB.QueryRow(
+		ctx,
+		`UPDATE categories SET name = $2 WHERE id = $1 RETURNING slug`,
+		c.Param("id"),
+		strings.TrimSpace(*input.Name),
+	).Scan(&slug)
+
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "category not found")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":   c.Param("id"),
+		"slug": slug,
+		"name": strings.TrimSpace(*input.Name),
+	})
+}
+
+func DeleteCategory(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := config.DB.Exec(ctx, `DELETE FROM categories WHERE id = $1`, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to delete category")})
+		return
+	}
+
+	if result.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "category not found")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "category deleted",
+	})
+}
+
+// SetEventCategories lets an admin correct the categories an organizer
+// picked.
+func SetEventCategories(c *gin.Context) {
+	var input struct {
+		Categories []string `json:"categories"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	slugs, ok := normalizeCategoriesInput(c, input.Categories)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var eventID int
+	err := config.DB.QueryRow(ctx, `SELECT id FROM events WHERE id = $1`, c.Param("id")).Scan(&eventID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "event not found")})
+		return
+	}
+
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to update categories")})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	err = setEventCategories(ctx, tx, eventID, slugs)
+	if errors.Is(err, errUnknownCategory) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "unknown category")})
+		return
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to update categories")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":         eventID,
+		"categories": slugs,
+	})
+}
@@ -0,0 +1,328 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"event-journal-backend/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxCollectionNameLength = 60
+
+type CollectionInput struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	IsPublic    *bool   `json:"is_public"`
+}
+
+// validateCollectionInput trims the name and writes the 400 response itself
+// when the input is invalid. requireName is set on create.
+func validateCollectionInput(c *gin.Context, input *CollectionInput, requireName bool) bool {
+	if input.Name == nil {
+		if requireName {
+			c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "collection name is required")})
+			return false
+		}
+		return true
+	}
+
+	name := strings.TrimSpace(*input.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "collection name is required")})
+		return false
+	}
+	if utf8.RuneCountInString(name) > maxCollectionNameLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "collection name is too long")})
+		return false
+	}
+
+	input.Name = &name
+	return true
+}
+
+type collectionRow struct {
+	ID          int
+	OwnerID     int
+	Name        string
+	Description *string
+	IsPublic    bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (r collectionRow) toJSON(itemCount int) gin.H {
+	return gin.H{
+		"id":          r.ID,
+		"name":        r.Name,
+		"description": r.Description,
+		"is_public":   r.IsPublic,
+		"item_count":  itemCount,
+		"created_at":  r.CreatedAt,
+		"updated_at":  r.UpdatedAt,
+	}
+}
+
+// listCollections returns the collections of ownerID, only public ones
+// unless includePrivate. item_count only counts journals the viewer may list.
+func listCollections(ctx context.Context, ownerID, viewerID int, includePrivate bool) ([]gin.H, error) {
+	query := `
+		SELECT bc.id, bc.user_id, bc.name, bc.description, bc.is_public, bc.created_at, bc.updated_at,
+		       (SELECT COUNT(*) FROM bookmarks b
+		        JOIN journals j ON j.id = b.journal_id
+		        WHERE b.collection_id = bc.id
+		          AND ` + journalListedSQL("j", "$2") + `)
+		FROM bookmark_collections bc
+		WHERE bc.user_id = $1
+		  AND ($3 OR bc.is_public = true)
+		ORDER BY bc.updated_at DESC
+	`
+
+	rows, err := config.DB.Query(ctx, query, ownerID, viewerID, includePrivate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var collections []gin.H
+
+	for rows.Next() {
+		var r collectionRow
+		var count int
+
+		if err := rows.Scan(&r.ID, &r.OwnerID, &r.Name, &r.Description, &r.IsPublic, &r.CreatedAt, &r.UpdatedAt, &count); err != nil {
+			return nil, err
+		}
+
+		collections = append(collections, r.toJSON(count))
+	}
+
+	return collections, rows.Err()
+}
+
+//
+// ===== MY COLLECTIONS =====
+//
+
+func CreateCollection(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	var input CollectionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !validateCollectionInput(c, &input, true) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO bookmark_collections (user_id, name, description, is_public)
+		VALUES ($1, $2, $3, COALESCE($4, false))
+		RETURNING id, user_id, name, description, is_public, created_at, updated_at
+	`
+
+	var r collectionRow
+	err := config.DB.QueryRow(ctx, query, userID, input.Name, input.Description, input.IsPublic).
+		Scan(&r.ID, &r.OwnerID, &r.Name, &r.Description, &r.IsPublic, &r.CreatedAt, &r.UpdatedAt)
+
+	if err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": tr(c, "you already have a collection with this name")})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to create collection")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, r.toJSON(0))
+}
+
+func GetMyCollections(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	collections, err := listCollections(ctx, userID, userID, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch collections")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": collections,
+	})
+}
+
+func UpdateCollection(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	var input CollectionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !validateCollectionInput(c, &input, false) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE bookmark_collections SET
+			name = COALESCE($3, name),
+			description = COALESCE($4, description),
+			is_public = COALESCE($5, is_public),
+			updated_at = NOW()
+		WHERE id = $1 AND user_id = $2
+		RETURNING id, user_id, name, description, is_public, created_at, updated_at
+	`
+
+	var r collectionRow
+	err := config.DB.QueryRow(ctx, query, c.Param("id"), userID, input.Name, input.Description, input.IsPublic).
+		Scan(&r.ID, &r.OwnerID, &r.Name, &r.Description, &r.IsPublic, &r.CreatedAt, &r.UpdatedAt)
+
+	if err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": tr(c, "you already have a collection with this name")})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "collection not found")})
+		return
+	}
+
+	var count int
+	countQuery := `
+		SELECT COUNT(*)
+		FROM bookmarks b
+		JOIN journals j ON j.id = b.journal_id
+		WHERE b.collection_id = $1
+		  AND ` + journalListedSQL("j", "$2") + `
+	`
+	_ = config.DB.QueryRow(ctx, countQuery, r.ID, userID).Scan(&count)
+
+	c.JSON(http.StatusOK, r.toJSON(count))
+}
+
+// DeleteCollection removes the collection only; its bookmarks fall back to
+// uncategorized.
+func DeleteCollection(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := config.DB.Exec(
+		ctx,
+		`DELETE FROM bookmark_collections WHERE id = $1 AND user_id = $2`,
+		c.Param("id"),
+		userID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to delete collection")})
+		return
+	}
+
+	if result.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "collection not found")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "collection deleted",
+	})
+}
+
+//
+// ===== SHARED COLLECTIONS =====
+//
+
+// GetCollection shows a collection to its owner, or to anyone when it is
+// public. Journals the viewer cannot see are left out.
+func GetCollection(c *gin.Context) {
+	viewerID := c.GetInt("user_id")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT bc.id, bc.user_id, bc.name, bc.description, bc.is_public, bc.created_at, bc.updated_at,
+		       ` + profileSummaryColumns("u") + `
+		FROM bookmark_collections bc
+		JOIN users u ON u.id = bc.user_id
+		WHERE bc.id = $1
+	`
+
+	var r collectionRow
+	var owner profileSummary
+
+	err := config.DB.QueryRow(ctx, query, c.Param("id")).Scan(
+		&r.ID,
+		&r.OwnerID,
+		&r.Name,
+		&r.Description,
+		&r.IsPublic,
+		&r.CreatedAt,
+		&r.UpdatedAt,
+		&owner.ID,
+		&owner.Username,
+		&owner.DisplayName,
+		&owner.AvatarURL,
+	)
+
+	// koleksi privat diperlakukan seperti tidak ada
+	if err != nil || (!r.IsPublic && r.OwnerID != viewerID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "collection not found")})
+		return
+	}
+
+	journals, err := listBookmarkedJournals(ctx, bookmarkFilter{
+		OwnerID:      r.OwnerID,
+		ViewerID:     viewerID,
+		CollectionID: &r.ID,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch bookmarks")})
+		return
+	}
+
+	collection := r.toJSON(len(journals))
+	collection["owner"] = owner
+	collection["journals"] = journals
+
+	c.JSON(http.StatusOK, collection)
+}
+
+func GetUserCollections(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ownerID, err := findUserIDByUsername(ctx, c.Param("username"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "user not found")})
+		return
+	}
+
+	viewerID := c.GetInt("user_id")
+
+	collections, err := listCollections(ctx, ownerID, viewerID, ownerID == viewerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch collections")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": collections,
+	})
+}
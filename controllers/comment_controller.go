@@ -3,121 +3,465 @@ package controllers
 import (
 	"context"
 	"net/http"
+	"strconv"
 	"time"
 
 	"event-journal-backend/config"
+	"event-journal-backend/services"
 
 	"github.com/gin-gonic/gin"
 )
 
 type CreateCommentInput struct {
+	Content  string `json:"content"`
+	ParentID *int   `json:"parent_id"` // kosong → komentar utama
+}
+
+type UpdateCommentInput struct {
 	Content string `json:"content"`
 }
 
+// commentNode is one comment in the thread returned to clients. Deleted
+// comments keep their place as a placeholder while they still have replies.
+type commentNode struct {
+	ID        int             `json:"id"`
+	ParentID  *int            `json:"parent_id"`
+	Content   string          `json:"content"`
+	User      *profileSummary `json:"user"`
+	Edited    bool            `json:"edited"`
+	Deleted   bool            `json:"deleted"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt *time.Time      `json:"updated_at"`
+
+	Reactions   map[string]int `json:"reactions"`
+	MyReactions []string       `json:"my_reactions"`
+
+	Replies []*commentNode `json:"replies"`
+}
+
+type commentRef struct {
+	ID             int
+	JournalID      int
+	UserID         int
+	JournalOwnerID int
+	Deleted        bool
+	Content        string
+}
+
+func findComment(ctx context.Context, commentID string) (commentRef, error) {
+	var comment commentRef
+
+	query := `
+		SELECT c.id, c.journal_id, c.user_id, j.user_id, c.deleted_at IS NOT NULL, c.content
+		FROM comments c
+		JOIN journals j ON j.id = c.journal_id
+		WHERE c.id = $1
+	`
+
+	err := config.DB.QueryRow(ctx, query, commentID).Scan(
+		&comment.ID,
+		&comment.JournalID,
+		&comment.UserID,
+		&comment.JournalOwnerID,
+		&comment.Deleted,
+		&comment.Content,
+	)
+
+	return comment, err
+}
+
+// loadCommentTree returns the comments of a journal nested by parent_id,
+// oldest first on every level, with reactions as seen by viewerID.
+func loadCommentTree(ctx context.Context, journalID, viewerID int) ([]*commentNode, error) {
+	query := `
+		SELECT
+			c.id,
+			c.parent_id,
+			c.content,
+			c.created_at,
+			c.updated_at,
+			c.deleted_at IS NOT NULL,
+			` + profileSummaryColumns("u") + `
+		FROM comments c
+		JOIN users u ON u.id = c.user_id
+		WHERE c.journal_id = $1
+		ORDER BY c.created_at ASC, c.id ASC
+	`
+
+	rows, err := config.DB.Query(ctx, query, journalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byID := map[int]*commentNode{}
+	var roots []*commentNode
+
+	for rows.Next() {
+		var node commentNode
+		var user profileSummary
+
+		if err := rows.Scan(
+			&node.ID,
+			&node.ParentID,
+			&node.Content,
+			&node.CreatedAt,
+			&node.UpdatedAt,
+			&node.Deleted,
+			&user.ID,
+			&user.Username,
+			&user.DisplayName,
+			&user.AvatarURL,
+		); err != nil {
+			return nil, err
+		}
+
+		node.Edited = node.UpdatedAt != nil
+		node.Replies = []*commentNode{}
+		if node.Deleted {
+			node.Content = ""
+		} else {
+			node.User = &user
+		}
+
+		byID[node.ID] = &node
+
+		if parent, ok := byID[derefInt(node.ParentID)]; ok {
+			parent.Replies = append(parent.Replies, &node)
+		} else {
+			roots = append(roots, &node)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	reactions, err := loadThreadReactions(ctx, journalID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	for id, node := range byID {
+		summary, ok := reactions[id]
+		if !ok {
+			summary = newReactionSummary()
+		}
+		node.Reactions, node.MyReactions = summary.Counts, summary.Mine
+	}
+
+	return pruneDeletedComments(roots), nil
+}
+
+// pruneDeletedComments drops deleted comments that no longer have any
+// visible reply underneath them.
+func pruneDeletedComments(nodes []*commentNode) []*commentNode {
+	kept := []*commentNode{}
+
+	for _, node := range nodes {
+		node.Replies = pruneDeletedComments(node.Replies)
+		if node.Deleted && len(node.Replies) == 0 {
+			continue
+		}
+		kept = append(kept, node)
+	}
+
+	return kept
+}
+
+func derefInt(v *int) int {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+//
+// ===== CREATE COMMENT =====
+//
+
 func CreateComment(c *gin.Context) {
 	journalID := c.Param("id")
-	userID, _ := c.Get("user_id")
+	userID := c.GetInt("user_id")
 
 	var input CreateCommentInput
 	if err := c.ShouldBindJSON(&input); err != nil || input.Content == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "content required"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "content required")})
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	journal, ok := findVisibleJournal(ctx, c, journalID)
+	if !ok {
+		return
+	}
+
+	// balasan harus ke komentar yang masih ada di jurnal yang sama
+	if input.ParentID != nil {
+		var exists bool
+		err := config.DB.QueryRow(
+			ctx,
+			`SELECT EXISTS (
+				SELECT 1 FROM comments
+				WHERE id = $1 AND journal_id = $2 AND deleted_at IS NULL
+			)`,
+			*input.ParentID,
+			journal.ID,
+		).Scan(&exists)
+
+		if err != nil || !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid parent_id")})
+			return
+		}
+	}
+
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to create comment")})
+		return
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
-		INSERT INTO comments (journal_id, user_id, content)
-		VALUES ($1, $2, $3)
+		INSERT INTO comments (journal_id, user_id, content, parent_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
 	`
 
-	_, err := config.DB.Exec(ctx, query, journalID, userID, input.Content)
+	var commentID int
+	err = tx.QueryRow(ctx, query, journal.ID, userID, input.Content, input.ParentID).Scan(&commentID)
+	if err == nil {
+		_, err = adjustJournalCounter(ctx, tx, journal.ID, "comment_count", 1)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create comment"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to create comment")})
 		return
 	}
 
+	go services.BumpJournalPopularity(journal.ID, services.PopularityComment)
+	go services.NotifyCommentMentions(commentID, journal.ID, userID, services.ExtractMentions(input.Content))
+
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "comment created",
+		"id":      commentID,
 	})
 }
 
+//
+// ===== LIST COMMENTS =====
+//
+
 func GetJournalComments(c *gin.Context) {
 	journalID := c.Param("id")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	journal, ok := findVisibleJournal(ctx, c, journalID)
+	if !ok {
+		return
+	}
+
+	comments, err := loadCommentTree(ctx, journal.ID, c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch comments")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": comments,
+	})
+}
+
+//
+// ===== EDIT COMMENT =====
+//
+
+func UpdateComment(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	var input UpdateCommentInput
+	if err := c.ShouldBindJSON(&input); err != nil || input.Content == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "content required")})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	comment, err := findComment(ctx, c.Param("id"))
+	if err != nil || comment.Deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "comment not found")})
+		return
+	}
+
+	if comment.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": tr(c, "not allowed")})
+		return
+	}
+
+	if _, ok := findVisibleJournal(ctx, c, strconv.Itoa(comment.JournalID)); !ok {
+		return
+	}
+
+	if input.Content == comment.Content {
+		c.JSON(http.StatusOK, gin.H{
+			"id":      comment.ID,
+			"content": comment.Content,
+		})
+		return
+	}
+
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to update comment")})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// simpan isi lama sebelum ditimpa
+	_, err = tx.Exec(
+		ctx,
+		`INSERT INTO comment_revisions (comment_id, content) VALUES ($1, $2)`,
+		comment.ID,
+		comment.Content,
+	)
+
+	var updatedAt time.Time
+	if err == nil {
+		err = tx.QueryRow(
+			ctx,
+			`UPDATE comments SET content = $1, updated_at = NOW() WHERE id = $2 RETURNING updated_at`,
+			input.Content,
+			comment.ID,
+		).Scan(&updatedAt)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to update comment")})
+		return
+	}
+
+	go services.NotifyCommentMentions(comment.ID, comment.JournalID, userID, services.ExtractMentions(input.Content))
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":         comment.ID,
+		"content":    input.Content,
+		"edited":     true,
+		"updated_at": updatedAt,
+	})
+}
+
+func GetCommentRevisions(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	comment, err := findComment(ctx, c.Param("id"))
+	if err != nil || comment.Deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "comment not found")})
+		return
+	}
+
+	if _, ok := findVisibleJournal(ctx, c, strconv.Itoa(comment.JournalID)); !ok {
+		return
+	}
+
 	query := `
-		SELECT 
-			c.id,
-			c.content,
-			c.created_at,
-			u.id,
-			u.email
-		FROM comments c
-		JOIN users u ON u.id = c.user_id
-		WHERE c.journal_id = $1
-		ORDER BY c.created_at ASC
+		SELECT content, edited_at
+		FROM comment_revisions
+		WHERE comment_id = $1
+		ORDER BY edited_at DESC, id DESC
 	`
 
-	rows, err := config.DB.Query(ctx, query, journalID)
+	rows, err := config.DB.Query(ctx, query, comment.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch comments"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch revisions")})
 		return
 	}
 	defer rows.Close()
 
-	var comments []gin.H
+	var revisions []gin.H
 
 	for rows.Next() {
-		var commentID, userID int
-		var content, email string
-		var createdAt time.Time
+		var content string
+		var editedAt time.Time
 
-		if err := rows.Scan(&commentID, &content, &createdAt, &userID, &email); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read comment"})
+		if err := rows.Scan(&content, &editedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch revisions")})
 			return
 		}
 
-		comments = append(comments, gin.H{
-			"id":         commentID,
-			"content":    content,
-			"created_at": createdAt,
-			"user": gin.H{
-				"id":    userID,
-				"email": email,
-			},
+		revisions = append(revisions, gin.H{
+			"content":   content,
+			"edited_at": editedAt,
 		})
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"data": comments,
+		"comment_id": comment.ID,
+		"content":    comment.Content,
+		"data":       revisions,
 	})
 }
 
+//
+// ===== DELETE COMMENT =====
+//
+
+// DeleteComment soft-deletes a comment. The author, the journal owner and
+// admins may delete; the row stays so replies keep their thread.
 func DeleteComment(c *gin.Context) {
-	commentID := c.Param("id")
-	userID, _ := c.Get("user_id")
+	userID := c.GetInt("user_id")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	comment, err := findComment(ctx, c.Param("id"))
+	if err != nil || comment.Deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "comment not found")})
+		return
+	}
+
+	if userID != comment.UserID && userID != comment.JournalOwnerID && c.GetString("role") != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": tr(c, "not allowed")})
+		return
+	}
+
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to delete comment")})
+		return
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
-		DELETE FROM comments
-		WHERE id = $1 AND user_id = $2
+		UPDATE comments
+		SET deleted_at = NOW(), deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	result, err := config.DB.Exec(ctx, query, commentID, userID)
+	result, err := tx.Exec(ctx, query, comment.ID, userID)
+	deleted := err == nil && result.RowsAffected() > 0
+	if deleted {
+		_, err = adjustJournalCounter(ctx, tx, comment.JournalID, "comment_count", -1)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete comment"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to delete comment")})
 		return
 	}
 
-	if result.RowsAffected() == 0 {
-		c.JSON(http.StatusForbidden, gin.H{"error": "not allowed"})
-		return
+	if deleted {
+		go services.BumpJournalPopularity(comment.JournalID, -services.PopularityComment)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
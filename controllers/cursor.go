@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Cursors are opaque to clients: base64("<score>:<id>") of the last item
+// on the previous page, for keyset pagination on (score, id).
+func encodeCursor(score float64, id int) string {
+	raw := strconv.FormatFloat(score, 'g', -1, 64) + ":" + strconv.Itoa(id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (float64, int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	scorePart, idPart, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return 0, 0, errors.New("malformed cursor")
+	}
+
+	score, err := strconv.ParseFloat(scorePart, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	id, err := strconv.Atoi(idPart)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return score, id, nil
+}
@@ -0,0 +1,22 @@
+package controllers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// isUniqueViolationOn reports a unique violation on a constraint or index
+// whose name contains name, e.g. "username".
+func isUniqueViolationOn(err error, name string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) &&
+		pgErr.Code == "23505" &&
+		strings.Contains(pgErr.ConstraintName, name)
+}
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"event-journal-backend/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func UpdateDigestPreference(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	var input struct {
+		Enabled *bool `json:"enabled" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE users
+		SET digest_opt_in = $1
+		WHERE id = $2
+	`
+
+	if _, err := config.DB.Exec(ctx, query, *input.Enabled, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to save digest preference")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"digest_enabled": *input.Enabled,
+	})
+}
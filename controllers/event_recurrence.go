@@ -0,0 +1,413 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"event-journal-backend/config"
+	"event-journal-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxEventOccurrences caps how many runs of one recurring event a
+	// search or detail response expands.
+	maxEventOccurrences = 500
+
+	upcomingOccurrencesLimit = 10
+	defaultOccurrenceWindow  = 90 * 24 * time.Hour
+)
+
+var errInvalidOccurrence = errors.New("invalid occurrence")
+
+// eventSchedule is when an organizer event happens: once from StartDate
+// to EndDate, repeated by Rule when the event is recurring.
+type eventSchedule struct {
+	ID        int
+	StartDate time.Time
+	EndDate   time.Time
+	Rule      *string
+	Timezone  string
+	CreatedBy *int
+}
+
+func loadEventSchedule(ctx context.Context, eventID int) (eventSchedule, error) {
+	s := eventSchedule{ID: eventID}
+
+	err := config.DB.QueryRow(
+		ctx,
+		`
+		SELECT start_date, end_date, recurrence_rule, timezone, created_by
+		FROM events
+		WHERE id = $1 AND event_type = 'organizer'
+		`,
+		eventID,
+	).Scan(&s.StartDate, &s.EndDate, &s.Rule, &s.Timezone, &s.CreatedBy)
+
+	return s, err
+}
+
+func (s eventSchedule) recurring() bool {
+	return s.Rule != nil
+}
+
+// recurrence parses the stored rule. Rules are validated on the way in,
+// so a failure here means the event is treated as a one-off.
+func (s eventSchedule) recurrence() (services.Recurrence, *time.Location, bool) {
+	if s.Rule == nil {
+		return services.Recurrence{}, nil, false
+	}
+
+	rule, err := services.ParseRecurrence(*s.Rule)
+	if err != nil {
+		return rule, nil, false
+	}
+
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return rule, nil, false
+	}
+
+	return rule, loc, true
+}
+
+// occurrences lists the runs of the event overlapping [from, to).
+func (s eventSchedule) occurrences(from, to time.Time, limit int) []services.Occurrence {
+	rule, loc, ok := s.recurrence()
+	if !ok {
+		if s.StartDate.Before(to) && s.EndDate.After(from) {
+			return []services.Occurrence{{Start: s.StartDate, End: s.EndDate}}
+		}
+		return nil
+	}
+
+	return rule.Occurrences(s.StartDate, s.EndDate.Sub(s.StartDate), loc, from, to, limit)
+}
+
+// includes reports whether a run of the event starts at start.
+func (s eventSchedule) includes(start time.Time) bool {
+	rule, loc, ok := s.recurrence()
+	if !ok {
+		return start.Equal(s.StartDate)
+	}
+	return rule.Includes(s.StartDate, loc, start)
+}
+
+// occurrenceKey identifies one run of an event. Timestamps are compared
+// in microseconds, the precision Postgres stores.
+type occurrenceKey struct {
+	EventID int
+	Start   int64
+}
+
+func keyOfOccurrence(eventID int, start time.Time) occurrenceKey {
+	return occurrenceKey{EventID: eventID, Start: start.UnixMicro()}
+}
+
+// loadOccurrenceCancellations returns the cancelled runs of eventIDs that
+// start in [from, to), with their reasons.
+func loadOccurrenceCancellations(ctx context.Context, eventIDs []int, from, to time.Time) (map[occurrenceKey]string, error) {
+	cancelled := map[occurrenceKey]string{}
+	if len(eventIDs) == 0 {
+		return cancelled, nil
+	}
+
+	rows, err := config.DB.Query(
+		ctx,
+		`
+		SELECT event_id, occurrence_start, reason
+		FROM event_occurrence_cancellations
+		WHERE event_id = ANY($1)
+		  AND occurrence_start >= $2
+		  AND occurrence_start < $3
+		`,
+		eventIDs,
+		from,
+		to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			eventID int
+			start   time.Time
+			reason  string
+		)
+		if err := rows.Scan(&eventID, &start, &reason); err != nil {
+			return nil, err
+		}
+		cancelled[keyOfOccurrence(eventID, start)] = reason
+	}
+
+	return cancelled, rows.Err()
+}
+
+func occurrenceJSON(eventID int, o services.Occurrence, cancelled map[occurrenceKey]string) gin.H {
+	reason, isCancelled := cancelled[keyOfOccurrence(eventID, o.Start)]
+
+	occurrence := gin.H{
+		"start":     o.Start,
+		"end":       o.End,
+		"cancelled": isCancelled,
+	}
+	if isCancelled {
+		occurrence["cancellation_reason"] = reason
+	}
+
+	return occurrence
+}
+
+// upcomingOccurrences lists the next runs of a recurring event (including
+// one in progress) for the event detail.
+func upcomingOccurrences(ctx context.Context, s eventSchedule) ([]gin.H, error) {
+	now := time.Now()
+	found := s.occurrences(now, now.AddDate(10, 0, 0), upcomingOccurrencesLimit)
+
+	upcoming := []gin.H{}
+	if len(found) == 0 {
+		return upcoming, nil
+	}
+
+	cancelled, err := loadOccurrenceCancellations(ctx, []int{s.ID}, found[0].Start, found[len(found)-1].Start.Add(time.Microsecond))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range found {
+		upcoming = append(upcoming, occurrenceJSON(s.ID, o, cancelled))
+	}
+
+	return upcoming, nil
+}
+
+// parseEventRecurrence validates the recurrence_rule and timezone of a
+// new organizer event. The rule is stored normalized; start_date must be
+// its first run, end_date sets how long every run lasts. It writes the
+// error response itself.
+func parseEventRecurrence(c *gin.Context, rule, timezone string, start, end time.Time) (*string, string, bool) {
+	if timezone == "" {
+		timezone = "UTC"
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid timezone")})
+		return nil, "", false
+	}
+
+	rule = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(rule)), "RRULE:")
+	if rule == "" {
+		return nil, timezone, true
+	}
+
+	recurrence, err := services.ParseRecurrence(rule)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid recurrence_rule")})
+		return nil, "", false
+	}
+
+	if !end.After(start) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "end_date must be after start_date")})
+		return nil, "", false
+	}
+
+	if !recurrence.StartsWith(start, loc) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "start_date must be the first occurrence of recurrence_rule")})
+		return nil, "", false
+	}
+
+	return &rule, timezone, true
+}
+
+// resolveJournalOccurrence picks the run of eventID a journal written at
+// writtenAt belongs to. An explicit occurrence_start must be a run of a
+// recurring event that isn't cancelled; without one, a journal written
+// while a run was in progress is attached to that run. One-off events
+// keep no occurrence.
+func resolveJournalOccurrence(ctx context.Context, eventID *int, requested *time.Time, writtenAt time.Time) (*time.Time, error) {
+	if eventID == nil {
+		if requested != nil {
+			return nil, errInvalidOccurrence
+		}
+		return nil, nil
+	}
+
+	s, err := loadEventSchedule(ctx, *eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.recurring() {
+		if requested != nil && !requested.Equal(s.StartDate) {
+			return nil, errInvalidOccurrence
+		}
+		return nil, nil
+	}
+
+	if requested != nil {
+		if !s.includes(*requested) {
+			return nil, errInvalidOccurrence
+		}
+
+		cancelled, err := loadOccurrenceCancellations(ctx, []int{s.ID}, *requested, requested.Add(time.Microsecond))
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := cancelled[keyOfOccurrence(s.ID, *requested)]; ok {
+			return nil, errInvalidOccurrence
+		}
+
+		return requested, nil
+	}
+
+	for _, o := range s.occurrences(writtenAt, writtenAt.Add(time.Microsecond), 1) {
+		start := o.Start
+		return &start, nil
+	}
+
+	return nil, nil
+}
+
+// parseDateParam reads a date query parameter given as RFC 3339 or as a
+// plain date (midnight UTC).
+func parseDateParam(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// ownedRecurringEvent loads a recurring event the current user may manage:
+// its organizer or an admin. It writes the error response itself.
+func ownedRecurringEvent(ctx context.Context, c *gin.Context) (eventSchedule, bool) {
+	eventID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid event id")})
+		return eventSchedule{}, false
+	}
+
+	s, err := loadEventSchedule(ctx, eventID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "event not found")})
+		return s, false
+	}
+
+	isOwner := s.CreatedBy != nil && *s.CreatedBy == c.GetInt("user_id")
+	if !isOwner && c.GetString("role") != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": tr(c, "not allowed")})
+		return s, false
+	}
+
+	if !s.recurring() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "event is not recurring")})
+		return s, false
+	}
+
+	return s, true
+}
+
+//
+// ===== OCCURRENCE CANCELLATION =====
+//
+
+// CancelEventOccurrence cancels one run of a recurring event; the rest of
+// the series is untouched.
+func CancelEventOccurrence(c *gin.Context) {
+	var input struct {
+		OccurrenceStart time.Time `json:"occurrence_start" binding:"required"`
+		Reason          string    `json:"reason"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	s, ok := ownedRecurringEvent(ctx, c)
+	if !ok {
+		return
+	}
+
+	if !s.includes(input.OccurrenceStart) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid occurrence_start")})
+		return
+	}
+
+	_, err := config.DB.Exec(
+		ctx,
+		`
+		INSERT INTO event_occurrence_cancellations (event_id, occurrence_start, reason, cancelled_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (event_id, occurrence_start)
+		DO UPDATE SET reason = EXCLUDED.reason
+		`,
+		s.ID,
+		input.OccurrenceStart,
+		strings.TrimSpace(input.Reason),
+		c.GetInt("user_id"),
+	)
+	if err != nil {
+		log.Println("FAILED TO CANCEL OCCURRENCE:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to cancel occurrence")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "occurrence cancelled",
+		"event_id":         s.ID,
+		"occurrence_start": input.OccurrenceStart,
+	})
+}
+
+// RestoreEventOccurrence undoes the cancellation of one run, given as
+// ?occurrence_start=.
+func RestoreEventOccurrence(c *gin.Context) {
+	start, err := time.Parse(time.RFC3339, c.Query("occurrence_start"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid occurrence_start")})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	s, ok := ownedRecurringEvent(ctx, c)
+	if !ok {
+		return
+	}
+
+	result, err := config.DB.Exec(
+		ctx,
+		`DELETE FROM event_occurrence_cancellations WHERE event_id = $1 AND occurrence_start = $2`,
+		s.ID,
+		start,
+	)
+	if err != nil {
+		log.Println("FAILED TO RESTORE OCCURRENCE:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to restore occurrence")})
+		return
+	}
+
+	if result.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "occurrence is not cancelled")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "occurrence restored",
+		"event_id":         s.ID,
+		"occurrence_start": start,
+	})
+}
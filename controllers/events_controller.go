@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"event-journal-backend/config"
+	"event-journal-backend/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -47,7 +48,7 @@ func CreateEvent(c *gin.Context) {
 	// business rule
 	if input.IsPaid && input.RegistrationURL == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "registration_url wajib untuk event berbayar",
+			"error": tr(c, "registration_url is required for paid events"),
 		})
 		return
 	}
@@ -84,7 +85,7 @@ func CreateEvent(c *gin.Context) {
 	).Scan(&eventID)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create event"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to create event")})
 		return
 	}
 
@@ -115,7 +116,7 @@ func GetMyEvents(c *gin.Context) {
 
 	rows, err := config.DB.Query(context.Background(), query, userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch events"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch events")})
 		return
 	}
 	defer rows.Close()
@@ -160,19 +161,22 @@ func GetEvents(c *gin.Context) {
 		e.location_name,
 		e.latitude,
 		e.longitude,
-		COUNT(j.id) AS journal_count
+		COUNT(j.id) AS journal_count,
+		` + services.DecayedScoreSQL("e", "$1") + ` AS score,
+		` + eventCategoriesSQL("e") + `
 	FROM events e
 	LEFT JOIN journals j 
 		ON j.event_id = e.id 
 		AND j.is_public = true
 	WHERE e.status = 'approved'
+	  AND ` + eventInCategorySQL("e", "$2") + `
 	GROUP BY e.id
-	ORDER BY journal_count DESC
+	ORDER BY score DESC, journal_count DESC
 	`
 
-	rows, err := config.DB.Query(ctx, query)
+	rows, err := config.DB.Query(ctx, query, services.PopularityDecayRate(), categoryQuery(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch events"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch events")})
 		return
 	}
 	defer rows.Close()
@@ -184,17 +188,21 @@ func GetEvents(c *gin.Context) {
 		var name string
 		var lat, lng float64
 		var count int
+		var score float64
+		var categories []string
 
-		if err := rows.Scan(&id, &name, &lat, &lng, &count); err != nil {
+		if err := rows.Scan(&id, &name, &lat, &lng, &count, &score, &categories); err != nil {
 			continue
 		}
 
 		events = append(events, gin.H{
-			"id":            id,
-			"location_name": name,
-			"latitude":      lat,
-			"longitude":     lng,
-			"journal_count": count,
+			"id":               id,
+			"location_name":    name,
+			"latitude":         lat,
+			"longitude":        lng,
+			"journal_count":    count,
+			"popularity_score": score,
+			"categories":       categories,
 		})
 	}
 
@@ -218,32 +226,37 @@ func GetEventDetail(c *gin.Context) {
 		Latitude     float64
 		Longitude    float64
 		LocationName string
+		Categories   []string
 	}
 
 	eventQuery := `
-	SELECT id, title, event_date, latitude, longitude, location_name
-	FROM events
-	WHERE id = $1 AND status = 'approved'
+	SELECT e.id, e.title, e.event_date, e.latitude, e.longitude, e.location_name,
+	       ` + eventCategoriesSQL("e") + `
+	FROM events e
+	WHERE e.id = $1 AND e.status = 'approved'
 	`
 
 	err := config.DB.QueryRow(ctx, eventQuery, eventID).
-		Scan(&event.ID, &event.Title, &event.EventDate, &event.Latitude, &event.Longitude, &event.LocationName)
+		Scan(&event.ID, &event.Title, &event.EventDate, &event.Latitude, &event.Longitude, &event.LocationName, &event.Categories)
 
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "event not found")})
 		return
 	}
 
+	go services.BumpEventPopularity(event.ID, services.PopularityView)
+
 	journalQuery := `
-	SELECT id, title, content, created_at
-	FROM journals
-	WHERE event_id = $1 AND is_public = true
-	ORDER BY created_at DESC
+	SELECT j.id, j.title, j.content, j.created_at, j.verified_at IS NOT NULL,
+	       ` + engagementColumns("j", "$2") + `
+	FROM journals j
+	WHERE j.event_id = $1 AND j.is_public = true
+	ORDER BY j.created_at DESC
 	`
 
-	rows, err := config.DB.Query(ctx, journalQuery, eventID)
+	rows, err := config.DB.Query(ctx, journalQuery, eventID, c.GetInt("user_id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch journals"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch journals")})
 		return
 	}
 	defer rows.Close()
@@ -254,17 +267,21 @@ func GetEventDetail(c *gin.Context) {
 		var id int
 		var title, content string
 		var createdAt time.Time
+		var verified bool
+		var engagement journalEngagement
 
-		if err := rows.Scan(&id, &title, &content, &createdAt); err != nil {
+		if err := rows.Scan(append([]any{&id, &title, &content, &createdAt, &verified}, engagement.scanTargets()...)...); err != nil {
 			continue
 		}
 
-		journals = append(journals, gin.H{
+		journals = append(journals, engagement.into(gin.H{
 			"id":         id,
 			"title":      title,
 			"content":    content,
 			"created_at": createdAt,
-		})
+
+			"verified_attendee": verified,
+		}))
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -303,12 +320,12 @@ func UpdateEvent(c *gin.Context) {
 	).Scan(&createdBy, &isPaid, &registrationURL)
 
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "event not found")})
 		return
 	}
 
 	if role != "admin" && createdBy != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "not allowed"})
+		c.JSON(http.StatusForbidden, gin.H{"error": tr(c, "not allowed")})
 		return
 	}
 
@@ -324,7 +341,7 @@ func UpdateEvent(c *gin.Context) {
 
 	if finalIsPaid && (finalRegistrationURL == nil || *finalRegistrationURL == "") {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "registration_url wajib untuk event berbayar",
+			"error": tr(c, "registration_url is required for paid events"),
 		})
 		return
 	}
@@ -364,7 +381,7 @@ func UpdateEvent(c *gin.Context) {
 	)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update event"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to update event")})
 		return
 	}
 
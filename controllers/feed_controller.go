@@ -0,0 +1,211 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"event-journal-backend/config"
+	"event-journal-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// satu "unit" engagement (ln(1+likes+comments)) setara 6 jam kesegaran
+	feedEngagementWeightHours = 6.0
+
+	feedNearbyWindow         = 30 * 24 * time.Hour
+	feedNearbyMinEngagements = 1
+)
+
+// GetFeed merges public journals from followed users, journals attached to
+// followed events and popular journals near lat/lng (when given), ranked by
+// recency plus engagement and paginated with an opaque cursor.
+func GetFeed(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 || limit > 50 {
+		limit = 20
+	}
+
+	var lat, lng *float64
+	if c.Query("lat") != "" && c.Query("lng") != "" {
+		parsedLat, errLat := strconv.ParseFloat(c.Query("lat"), 64)
+		parsedLng, errLng := strconv.ParseFloat(c.Query("lng"), 64)
+		if errLat != nil || errLng != nil || !validCoordinates(parsedLat, parsedLng) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid coordinates")})
+			return
+		}
+		lat, lng = &parsedLat, &parsedLng
+	}
+
+	radius, err := strconv.ParseFloat(c.DefaultQuery("radius", "10"), 64)
+	if err != nil || radius <= 0 {
+		radius = 10
+	}
+
+	var cursorScore *float64
+	var cursorID *int
+	if cursor := c.Query("cursor"); cursor != "" {
+		score, id, err := decodeCursor(cursor)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid cursor")})
+			return
+		}
+		cursorScore, cursorID = &score, &id
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `
+	WITH candidates AS (
+		SELECT j.id, 'following' AS reason
+		FROM journals j
+		JOIN user_follows f ON f.followee_id = j.user_id
+		WHERE f.follower_id = $1
+		  AND j.visibility IN ('public', 'followers')
+		  AND j.status = 'published'
+
+		UNION ALL
+
+		SELECT j.id, 'followed_event'
+		FROM journals j
+		JOIN event_follows ef ON ef.event_id = j.event_id
+		WHERE ef.user_id = $1
+		  AND j.is_public = true
+		  AND j.user_id <> $1
+
+		UNION ALL
+
+		SELECT j.id, 'nearby'
+		FROM journals j
+		WHERE $2::float8 IS NOT NULL
+		  AND j.is_public = true
+		  AND j.user_id <> $1
+		  AND j.created_at > $5
+		  AND j.latitude IS NOT NULL
+		  AND j.longitude IS NOT NULL
+		  AND ` + services.DistanceKmSQL("$2", "$3", "j.latitude", "j.longitude") + ` <= $4
+	),
+	grouped AS (
+		SELECT id, array_agg(DISTINCT reason) AS reasons
+		FROM candidates
+		GROUP BY id
+	),
+	ranked AS (
+		SELECT
+			j.id, j.user_id, j.event_id, j.title, j.content,
+			j.latitude, j.longitude, j.created_at,
+			g.reasons,
+			(
+				EXTRACT(EPOCH FROM j.created_at) / 3600.0
+				+ $6 * LN(1 + j.like_count + j.comment_count)
+			)::float8 AS score
+		FROM grouped g
+		JOIN journals j ON j.id = g.id
+		-- jurnal yang hanya "nearby" harus populer
+		WHERE g.reasons <> ARRAY['nearby']
+		   OR j.like_count + j.comment_count >= $7
+	)
+	SELECT
+		r.id, r.event_id, r.title, r.content, r.latitude, r.longitude,
+		r.created_at, r.reasons, r.score,
+		` + engagementColumns("j", "$1") + `,
+		` + profileSummaryColumns("u") + `
+	FROM ranked r
+	JOIN journals j ON j.id = r.id
+	JOIN users u ON u.id = r.user_id
+	WHERE $8::float8 IS NULL OR (r.score, r.id) < ($8, $9)
+	ORDER BY r.score DESC, r.id DESC
+	LIMIT $10
+	`
+
+	rows, err := config.DB.Query(
+		ctx,
+		query,
+		userID,
+		lat,
+		lng,
+		radius,
+		time.Now().Add(-feedNearbyWindow),
+		feedEngagementWeightHours,
+		feedNearbyMinEngagements,
+		cursorScore,
+		cursorID,
+		limit+1,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch feed")})
+		return
+	}
+	defer rows.Close()
+
+	var (
+		items     []gin.H
+		hasMore   bool
+		lastScore float64
+		lastID    int
+	)
+
+	for rows.Next() {
+		var (
+			id        int
+			eventID   *int
+			title     string
+			content   string
+			jLat      *float64
+			jLng      *float64
+			createdAt time.Time
+			reasons   []string
+			score     float64
+
+			engagement journalEngagement
+			author     profileSummary
+		)
+
+		if err := rows.Scan(append(
+			append([]any{&id, &eventID, &title, &content, &jLat, &jLng, &createdAt, &reasons, &score}, engagement.scanTargets()...),
+			&author.ID,
+			&author.Username,
+			&author.DisplayName,
+			&author.AvatarURL,
+		)...); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to read journal")})
+			return
+		}
+
+		// baris ke limit+1 hanya penanda masih ada halaman berikutnya
+		if len(items) == limit {
+			hasMore = true
+			break
+		}
+
+		items = append(items, engagement.into(gin.H{
+			"id":         id,
+			"event_id":   eventID,
+			"title":      title,
+			"content":    content,
+			"latitude":   jLat,
+			"longitude":  jLng,
+			"created_at": createdAt,
+			"author":     author,
+			"reasons":    reasons,
+		}))
+		lastScore, lastID = score, id
+	}
+
+	var nextCursor *string
+	if hasMore {
+		cursor := encodeCursor(lastScore, lastID)
+		nextCursor = &cursor
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":        items,
+		"next_cursor": nextCursor,
+	})
+}
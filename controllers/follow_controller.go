@@ -0,0 +1,249 @@
+package controllers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"event-journal-backend/config"
+	"event-journal-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func findUserIDByUsername(ctx context.Context, username string) (int, error) {
+	var id int
+	err := config.DB.QueryRow(
+		ctx,
+		`SELECT id FROM users WHERE LOWER(username) = $1`,
+		strings.ToLower(username),
+	).Scan(&id)
+
+	return id, err
+}
+
+//
+// ===== FOLLOW USERS =====
+//
+
+func FollowUser(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	targetID, err := findUserIDByUsername(ctx, c.Param("username"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "user not found")})
+		return
+	}
+
+	if targetID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "you cannot follow yourself")})
+		return
+	}
+
+	query := `
+		INSERT INTO user_follows (follower_id, followee_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+
+	if _, err := config.DB.Exec(ctx, query, userID, targetID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to follow user")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"following": true,
+	})
+}
+
+func UnfollowUser(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	targetID, err := findUserIDByUsername(ctx, c.Param("username"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "user not found")})
+		return
+	}
+
+	query := `
+		DELETE FROM user_follows
+		WHERE follower_id = $1 AND followee_id = $2
+	`
+
+	if _, err := config.DB.Exec(ctx, query, userID, targetID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to unfollow user")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"following": false,
+	})
+}
+
+// listFollows serves both followers and following lists; direction is
+// "followers" or "following".
+func listFollows(c *gin.Context, direction string) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 50 {
+		limit = 20
+	}
+
+	offset := (page - 1) * limit
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	targetID, err := findUserIDByUsername(ctx, c.Param("username"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "user not found")})
+		return
+	}
+
+	// followers: siapa yang follow target; following: siapa yang di-follow target
+	matchColumn, otherColumn := "followee_id", "follower_id"
+	if direction == "following" {
+		matchColumn, otherColumn = "follower_id", "followee_id"
+	}
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM user_follows WHERE ` + matchColumn + ` = $1`
+	_ = config.DB.QueryRow(ctx, countQuery, targetID).Scan(&total)
+
+	query := `
+		SELECT ` + profileSummaryColumns("u") + `, f.created_at
+		FROM user_follows f
+		JOIN users u ON u.id = f.` + otherColumn + `
+		WHERE f.` + matchColumn + ` = $1
+		ORDER BY f.created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := config.DB.Query(ctx, query, targetID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch users")})
+		return
+	}
+	defer rows.Close()
+
+	var users []gin.H
+
+	for rows.Next() {
+		var user profileSummary
+		var since time.Time
+
+		if err := rows.Scan(&user.ID, &user.Username, &user.DisplayName, &user.AvatarURL, &since); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch users")})
+			return
+		}
+
+		users = append(users, gin.H{
+			"user":  user,
+			"since": since,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": users,
+		"pagination": gin.H{
+			"page":        page,
+			"limit":       limit,
+			"total":       total,
+			"total_pages": int(math.Ceil(float64(total) / float64(limit))),
+		},
+	})
+}
+
+func GetFollowers(c *gin.Context) {
+	listFollows(c, "followers")
+}
+
+func GetFollowing(c *gin.Context) {
+	listFollows(c, "following")
+}
+
+//
+// ===== FOLLOW EVENTS =====
+//
+
+func FollowEvent(c *gin.Context) {
+	eventID := c.Param("id")
+	userID := c.GetInt("user_id")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var exists bool
+	err := config.DB.QueryRow(
+		ctx,
+		`SELECT EXISTS (SELECT 1 FROM events WHERE id = $1 AND status = 'approved')`,
+		eventID,
+	).Scan(&exists)
+
+	if err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "event not found")})
+		return
+	}
+
+	query := `
+		INSERT INTO event_follows (user_id, event_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+
+	result, err := config.DB.Exec(ctx, query, userID, eventID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to follow event")})
+		return
+	}
+
+	if result.RowsAffected() > 0 {
+		id, _ := strconv.Atoi(eventID)
+		go services.BumpEventPopularity(id, services.PopularityFollow)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"following": true,
+	})
+}
+
+func UnfollowEvent(c *gin.Context) {
+	eventID := c.Param("id")
+	userID := c.GetInt("user_id")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `
+		DELETE FROM event_follows
+		WHERE user_id = $1 AND event_id = $2
+	`
+
+	result, err := config.DB.Exec(ctx, query, userID, eventID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to unfollow event")})
+		return
+	}
+
+	if result.RowsAffected() > 0 {
+		id, _ := strconv.Atoi(eventID)
+		go services.BumpEventPopularity(id, -services.PopularityFollow)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"following": false,
+	})
+}
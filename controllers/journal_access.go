@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"event-journal-backend/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	VisibilityPrivate   = "private"
+	VisibilityFollowers = "followers"
+	VisibilityUnlisted  = "unlisted"
+	VisibilityPublic    = "public"
+)
+
+const (
+	StatusDraft     = "draft"
+	StatusScheduled = "scheduled"
+	StatusPublished = "published"
+)
+
+func validVisibility(v string) bool {
+	switch v {
+	case VisibilityPrivate, VisibilityFollowers, VisibilityUnlisted, VisibilityPublic:
+		return true
+	}
+	return false
+}
+
+type journalAccess struct {
+	ID         int
+	OwnerID    int
+	Visibility string
+	Status     string
+}
+
+// journalVisibleSQL is a WHERE condition for journals under alias that the
+// user in viewerParam (0 for anonymous) may open. Unlisted journals count
+// as visible: anyone holding the link can read them. Drafts and scheduled
+// journals are only visible to their author.
+func journalVisibleSQL(alias, viewerParam string) string {
+	return "(" + alias + ".user_id = " + viewerParam +
+		" OR (" + alias + ".status = 'published' AND (" +
+		alias + ".visibility IN ('public', 'unlisted')" +
+		" OR (" + alias + ".visibility = 'followers' AND EXISTS (" +
+		"SELECT 1 FROM user_follows vf WHERE vf.follower_id = " + viewerParam +
+		" AND vf.followee_id = " + alias + ".user_id)))))"
+}
+
+// journalListedSQL is journalVisibleSQL for listings (collections,
+// bookmarks, trips): an unlisted journal only shows up for its author, so
+// putting it in a list never hands the link to anyone else.
+func journalListedSQL(alias, viewerParam string) string {
+	return "(" + journalVisibleSQL(alias, viewerParam) +
+		" AND (" + alias + ".visibility <> 'unlisted' OR " + alias + ".user_id = " + viewerParam + "))"
+}
+
+// findVisibleJournal loads journalID and checks that the current viewer
+// (user_id may be unset) is allowed to see it. It writes the 404/403
+// response itself and returns false when not.
+func findVisibleJournal(ctx context.Context, c *gin.Context, journalID string) (journalAccess, bool) {
+	var journal journalAccess
+	var visible bool
+
+	query := `
+		SELECT j.id, j.user_id, j.visibility, j.status, ` + journalVisibleSQL("j", "$2") + `
+		FROM journals j
+		WHERE j.id = $1
+	`
+
+	err := config.DB.QueryRow(ctx, query, journalID, c.GetInt("user_id")).
+		Scan(&journal.ID, &journal.OwnerID, &journal.Visibility, &journal.Status, &visible)
+
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "journal not found")})
+		return journal, false
+	}
+
+	if !visible {
+		c.JSON(http.StatusForbidden, gin.H{"error": tr(c, "this journal is private")})
+		return journal, false
+	}
+
+	return journal, true
+}
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"event-journal-backend/services"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// occurredAtClockSkew is how far a device clock may run ahead: an
+// occurred_at up to this much after the journal was created is taken as
+// the creation time.
+const occurredAtClockSkew = 5 * time.Minute
+
+// checkOccurredAt bounds the occurred_at of a journal created at
+// createdAt, since the check-in trusts it. It may not be later than
+// createdAt, nor older than an offline journal can be (the tombstone
+// retention, same as a sync token). It returns the value to store, or the
+// problem.
+func checkOccurredAt(occurredAt, createdAt time.Time) (time.Time, string) {
+	switch {
+	case occurredAt.After(time.Now().Add(occurredAtClockSkew)):
+		return occurredAt, "occurred_at is in the future"
+	case occurredAt.After(createdAt.Add(occurredAtClockSkew)):
+		return occurredAt, "occurred_at is after the journal was created"
+	case createdAt.Sub(occurredAt) > services.SyncTombstoneRetention():
+		return occurredAt, "occurred_at is too far in the past"
+	case occurredAt.After(createdAt):
+		return createdAt, ""
+	}
+	return occurredAt, ""
+}
+
+// checkInJournal decides whether the journal is a verified check-in: it
+// was written (occurred_at, else created_at) while its event, or for a
+// recurring event the journal's occurrence, was running and not
+// cancelled, and its location lies within CHECKIN_RADIUS_KM of the event.
+// Call it when the journal is created and whenever its location or
+// occurred_at changes; since the time test uses the journal's own
+// timestamp, fixing a pin after the event keeps a badge that was earned.
+func checkInJournal(ctx context.Context, tx pgx.Tx, journalID int) (*time.Time, error) {
+	query := `
+		UPDATE journals j SET
+			verified_at = (
+				SELECT COALESCE(j.verified_at, NOW())
+				FROM events e
+				WHERE e.id = j.event_id
+				  AND j.latitude IS NOT NULL
+				  AND j.longitude IS NOT NULL
+				  AND e.start_date IS NOT NULL
+				  AND e.end_date IS NOT NULL
+				  AND COALESCE(j.occurred_at, j.created_at) BETWEEN COALESCE(j.occurrence_start, e.start_date)
+				      AND COALESCE(j.occurrence_start, e.start_date) + (e.end_date - e.start_date)
+				  AND NOT EXISTS (
+				    SELECT 1 FROM event_occurrence_cancellations oc
+				    WHERE oc.event_id = e.id
+				      AND oc.occurrence_start = COALESCE(j.occurrence_start, e.start_date)
+				  )
+				  AND ` + services.DistanceKmSQL("e.latitude", "e.longitude", "j.latitude", "j.longitude") + ` <= $2
+			)
+		WHERE j.id = $1
+		RETURNING j.verified_at
+	`
+
+	var verifiedAt *time.Time
+	err := tx.QueryRow(ctx, query, journalID, services.CheckInRadiusKm()).Scan(&verifiedAt)
+
+	return verifiedAt, err
+}
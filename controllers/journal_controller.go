@@ -2,14 +2,18 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"math"
 	"net/http"
 	"strconv"
 	"time"
 
 	"event-journal-backend/config"
+	"event-journal-backend/services"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 )
 
 type CreateJournalInput struct {
@@ -23,12 +27,23 @@ type CreateJournalInput struct {
 
 func CreateJournal(c *gin.Context) {
 	var input struct {
-		EventID   *int    `json:"event_id"` // pointer → optional
-		Title     string  `json:"title" binding:"required"`
-		Content   string  `json:"content"`
-		Latitude  float64 `json:"latitude"`
-		Longitude float64 `json:"longitude"`
-		IsPublic  bool    `json:"is_public"`
+		EventID    *int    `json:"event_id"` // pointer → optional
+		Title      string  `json:"title" binding:"required"`
+		Content    string  `json:"content"`
+		Latitude   float64 `json:"latitude"`
+		Longitude  float64 `json:"longitude"`
+		IsPublic   bool    `json:"is_public"`  // lama, dipakai kalau visibility kosong
+		Visibility string  `json:"visibility"` // private | followers | unlisted | public
+
+		ContentFormat string          `json:"content_format"` // plain (default) | markdown | blocks
+		Document      json.RawMessage `json:"document"`       // isi untuk format blocks
+
+		Status    string     `json:"status"`     // draft | published (default)
+		PublishAt *time.Time `json:"publish_at"` // di masa depan → dijadwalkan
+
+		Tags []string `json:"tags"`
+
+		OccurrenceStart *time.Time `json:"occurrence_start"` // kejadian tertentu dari event berulang
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -38,82 +53,269 @@ func CreateJournal(c *gin.Context) {
 
 	userID := c.GetInt("user_id")
 
-	// 🔐 public journal wajib punya lokasi
-	if input.IsPublic && (input.Latitude == 0 || input.Longitude == 0) {
+	if input.Visibility == "" {
+		input.Visibility = VisibilityPrivate
+		if input.IsPublic {
+			input.Visibility = VisibilityPublic
+		}
+	}
+
+	if !validVisibility(input.Visibility) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid visibility")})
+		return
+	}
+
+	if input.ContentFormat == "" {
+		input.ContentFormat = services.ContentFormatPlain
+	}
+
+	if !services.ValidContentFormat(input.ContentFormat) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid content_format")})
+		return
+	}
+
+	// dokumen blok disimpan apa adanya di kolom content
+	if input.ContentFormat == services.ContentFormatBlocks {
+		input.Content = string(input.Document)
+	}
+
+	status, ok := resolvePublishStatus(c, input.Status, input.PublishAt)
+	if !ok {
+		return
+	}
+
+	tags, ok := normalizeTagsInput(c, input.Tags)
+	if !ok {
+		return
+	}
+
+	// 🔐 public journal wajib punya lokasi (draft boleh menyusul)
+	if status != StatusDraft && input.Visibility == VisibilityPublic && (input.Latitude == 0 || input.Longitude == 0) {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "public journal must have location",
+			"error": tr(c, "public journal must have location"),
 		})
 		return
 	}
 
 	// 🔎 VALIDASI EVENT (JIKA ADA)
-	if input.EventID != nil {
-		var exists bool
-		err := config.DB.QueryRow(
-			context.Background(),
-			`
-			SELECT EXISTS (
-				SELECT 1 FROM events
-				WHERE id = $1 AND event_type = 'organizer'
-			)
-			`,
-			*input.EventID,
-		).Scan(&exists)
+	if input.EventID != nil && !organizerEventExists(context.Background(), *input.EventID) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": tr(c, "invalid event_id"),
+		})
+		return
+	}
 
-		if err != nil || !exists {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "invalid event_id",
-			})
-			return
-		}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	occurrenceStart, err := resolveJournalOccurrence(ctx, input.EventID, input.OccurrenceStart, time.Now())
+	if errors.Is(err, errInvalidOccurrence) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid occurrence_start")})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	journal := newJournal{
+		EventID:         input.EventID,
+		OccurrenceStart: occurrenceStart,
+		Title:           input.Title,
+		Content:         input.Content,
+		ContentFormat:   input.ContentFormat,
+		Latitude:        input.Latitude,
+		Longitude:       input.Longitude,
+		Visibility:      input.Visibility,
+		Status:          status,
+		PublishAt:       scheduledAt(status, input.PublishAt),
+		Tags:            tags,
 	}
 
+	created, err := insertJournal(ctx, tx, userID, journal)
+	if errors.Is(err, services.ErrInvalidContent) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid content")})
+		return
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// draft & jadwal baru dihitung saat benar-benar terbit
+	if status == StatusPublished {
+		announceJournal(created.ID, input.EventID)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":      "journal created",
+		"id":           created.ID,
+		"status":       status,
+		"publish_at":   journal.PublishAt,
+		"revision":     1,
+		"content_html": created.Rendered.HTML,
+		"excerpt":      created.Rendered.Excerpt,
+		"tags":         tags,
+
+		"occurrence_start":  occurrenceStart,
+		"verified_attendee": created.VerifiedAt != nil,
+		"verified_at":       created.VerifiedAt,
+	})
+}
+
+// organizerEventExists reports whether journals can be attached to
+// eventID.
+func organizerEventExists(ctx context.Context, eventID int) bool {
+	var exists bool
+	err := config.DB.QueryRow(
+		ctx,
+		`
+		SELECT EXISTS (
+			SELECT 1 FROM events
+			WHERE id = $1 AND event_type = 'organizer'
+		)
+		`,
+		eventID,
+	).Scan(&exists)
+
+	return err == nil && exists
+}
+
+// newJournal is a validated journal ready to be inserted.
+type newJournal struct {
+	ClientID        *string // UUID dari app offline
+	EventID         *int
+	OccurrenceStart *time.Time // kejadian event berulang
+	OccurredAt      *time.Time // waktu ditulis di perangkat (sync offline)
+	Title           string
+	Content         string
+	ContentFormat   string
+	Latitude        float64
+	Longitude       float64
+	Visibility      string
+	Status          string
+	PublishAt       *time.Time
+	Tags            []string
+}
+
+type createdJournal struct {
+	ID         int
+	Rendered   services.RenderedContent
+	VerifiedAt *time.Time
+}
+
+// insertJournal writes j as revision 1 of a new journal of userID: it
+// renders the content, sets the tags, checks the author in at the event
+// and records the first revision. An unrenderable document gives
+// ErrInvalidContent.
+func insertJournal(ctx context.Context, tx pgx.Tx, userID int, j newJournal) (createdJournal, error) {
+	var created createdJournal
+
 	query := `
 		INSERT INTO journals (
-			user_id, event_id, title, content,
-			latitude, longitude, is_public
+			user_id, event_id, title, content, content_format,
+			latitude, longitude, visibility,
+			status, publish_at, published_at, client_id,
+			occurrence_start, occurred_at
 		)
-		VALUES ($1,$2,$3,$4,$5,$6,$7)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,
+			CASE WHEN $9 = 'published' THEN NOW() END, $11, $12, $13)
+		RETURNING id
 	`
 
-	_, err := config.DB.Exec(
-		context.Background(),
+	err := tx.QueryRow(
+		ctx,
 		query,
 		userID,
-		input.EventID,
-		input.Title,
-		input.Content,
-		input.Latitude,
-		input.Longitude,
-		input.IsPublic,
-	)
+		j.EventID,
+		j.Title,
+		j.Content,
+		j.ContentFormat,
+		j.Latitude,
+		j.Longitude,
+		j.Visibility,
+		j.Status,
+		j.PublishAt,
+		j.ClientID,
+		j.OccurrenceStart,
+		j.OccurredAt,
+	).Scan(&created.ID)
+	if err != nil {
+		return created, err
+	}
 
+	// HTML butuh id jurnal untuk URL gambar, jadi dirender setelah insert
+	created.Rendered, err = renderJournalContent(created.ID, j.ContentFormat, j.Content)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+		return created, services.ErrInvalidContent
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"message": "journal created",
+	_, err = tx.Exec(
+		ctx,
+		`UPDATE journals SET content_html = $2, excerpt = $3 WHERE id = $1`,
+		created.ID,
+		created.Rendered.HTML,
+		created.Rendered.Excerpt,
+	)
+	if err == nil {
+		err = setJournalTags(ctx, tx, created.ID, j.Tags)
+	}
+
+	// 📍 check-in: lokasi & waktu cocok dengan event
+	if err == nil {
+		created.VerifiedAt, err = checkInJournal(ctx, tx, created.ID)
+	}
+	if err == nil {
+		err = saveJournalRevision(ctx, tx, created.ID, userID)
+	}
+
+	return created, err
+}
+
+// renderJournalContent renders journal source with image references
+// pointing at the guarded journal image URLs.
+func renderJournalContent(journalID int, format, source string) (services.RenderedContent, error) {
+	return services.RenderContent(format, source, func(imageID int) string {
+		return journalImageURL(journalID, imageID)
 	})
 }
 
 func GetMyJournals(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 
+	tag, ok := tagQuery(c)
+	if !ok {
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	query := `
-		SELECT id, title, content, created_at
-		FROM journals
-		WHERE user_id = $1
-		ORDER BY created_at DESC
+		SELECT j.id, j.title, j.content, j.content_format, j.excerpt, j.visibility, j.created_at,
+		       j.status, j.publish_at, j.revision, j.updated_at,
+		       ` + journalTagsSQL("j") + `, j.verified_at IS NOT NULL,
+		       ` + engagementColumns("j", "$1") + `
+		FROM journals j
+		WHERE j.user_id = $1
+		  AND ($2 = '' OR j.status = $2)
+		  AND ` + journalHasTagSQL("j", "$3") + `
+		ORDER BY j.updated_at DESC
 	`
 
-	rows, err := config.DB.Query(ctx, query, userID)
+	rows, err := config.DB.Query(ctx, query, userID, c.Query("status"), tag)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch journals"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch journals")})
 		return
 	}
 	defer rows.Close()
@@ -122,21 +324,39 @@ func GetMyJournals(c *gin.Context) {
 
 	for rows.Next() {
 		var id int
-		var title, content string
-		var createdAt time.Time
-
-		err := rows.Scan(&id, &title, &content, &createdAt)
+		var title, content, contentFormat, excerpt, visibility, status string
+		var createdAt, updatedAt time.Time
+		var publishAt *time.Time
+		var revision int
+		var tags []string
+		var verified bool
+		var engagement journalEngagement
+
+		err := rows.Scan(append(
+			[]any{&id, &title, &content, &contentFormat, &excerpt, &visibility, &createdAt, &status, &publishAt, &revision, &updatedAt, &tags, &verified},
+			engagement.scanTargets()...,
+		)...)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read journal"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to read journal")})
 			return
 		}
 
-		journals = append(journals, gin.H{
-			"id":         id,
-			"title":      title,
-			"content":    content,
-			"created_at": createdAt,
-		})
+		journals = append(journals, engagement.into(gin.H{
+			"id":             id,
+			"title":          title,
+			"content":        content,
+			"content_format": contentFormat,
+			"excerpt":        excerpt,
+			"visibility":     visibility,
+			"created_at":     createdAt,
+			"status":         status,
+			"publish_at":     publishAt,
+			"revision":       revision,
+			"updated_at":     updatedAt,
+			"tags":           tags,
+
+			"verified_attendee": verified,
+		}))
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -147,32 +367,33 @@ func GetPublicJournals(c *gin.Context) {
 	lat := c.Query("lat")
 	lng := c.Query("lng")
 	radius := c.DefaultQuery("radius", "5")
+	viewerID := c.GetInt("user_id")
+
+	tag, ok := tagQuery(c)
+	if !ok {
+		return
+	}
 
 	query := `
-		SELECT id, title, content, latitude, longitude, created_at
-		FROM journals
-		WHERE is_public = true
-		  AND latitude IS NOT NULL
-		  AND longitude IS NOT NULL
-		  AND (
-		    6371 * acos(
-		      cos(radians($1)) *
-		      cos(radians(latitude)) *
-		      cos(radians(longitude) - radians($2)) +
-		      sin(radians($1)) *
-		      sin(radians(latitude))
-		    )
-		  ) <= $3
-		ORDER BY created_at DESC
+		SELECT j.id, j.title, j.content, j.excerpt, j.latitude, j.longitude, j.created_at,
+		       ` + journalTagsSQL("j") + `, j.verified_at IS NOT NULL,
+		       ` + engagementColumns("j", "$4") + `
+		FROM journals j
+		WHERE j.is_public = true
+		  AND j.latitude IS NOT NULL
+		  AND j.longitude IS NOT NULL
+		  AND ` + services.DistanceKmSQL("$1", "$2", "j.latitude", "j.longitude") + ` <= $3
+		  AND ` + journalHasTagSQL("j", "$5") + `
+		ORDER BY j.created_at DESC
 	`
 
 	rows, err := config.DB.Query(
 		context.Background(),
 		query,
-		lat, lng, radius,
+		lat, lng, radius, viewerID, tag,
 	)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch public journals"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch public journals")})
 		return
 	}
 	defer rows.Close()
@@ -181,20 +402,27 @@ func GetPublicJournals(c *gin.Context) {
 
 	for rows.Next() {
 		var id int
-		var title, content string
+		var title, content, excerpt string
 		var lat, lng float64
 		var createdAt time.Time
+		var tags []string
+		var verified bool
+		var engagement journalEngagement
 
-		rows.Scan(&id, &title, &content, &lat, &lng, &createdAt)
+		rows.Scan(append([]any{&id, &title, &content, &excerpt, &lat, &lng, &createdAt, &tags, &verified}, engagement.scanTargets()...)...)
 
-		journals = append(journals, gin.H{
+		journals = append(journals, engagement.into(gin.H{
 			"id":         id,
 			"title":      title,
 			"content":    content,
+			"excerpt":    excerpt,
 			"latitude":   lat,
 			"longitude":  lng,
 			"created_at": createdAt,
-		})
+			"tags":       tags,
+
+			"verified_attendee": verified,
+		}))
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -204,137 +432,214 @@ func GetPublicJournals(c *gin.Context) {
 func GetJournalDetail(c *gin.Context) {
 	journalID := c.Param("id")
 
-	bookmarked := false
 	// optional: user login atau enggak
-	userID, _ := c.Get("user_id")
+	userID := c.GetInt("user_id")
 
 	query := `
 		SELECT 
 			j.id,
 			j.title,
 			j.content,
+			j.content_format,
+			j.content_html,
+			j.excerpt,
 			j.latitude,
 			j.longitude,
-			j.is_public,
+			j.visibility,
+			` + journalVisibleSQL("j", "$2") + `,
 			j.created_at,
-			u.id AS author_id,
-			u.email
+			j.view_count,
+			j.status,
+			j.published_at,
+			j.revision,
+			j.updated_at,
+			j.trip_id,
+			COALESCE(j.occurred_at, j.created_at),
+			` + journalTagsSQL("j") + `,
+			j.verified_at,
+			j.occurrence_start,
+			` + engagementColumns("j", "$2") + `,
+			` + profileSummaryColumns("u") + `
 		FROM journals j
 		JOIN users u ON u.id = j.user_id
 		WHERE j.id = $1
 	`
 
 	var (
-		id        int
-		title     string
-		content   string
-		lat       float64
-		lng       float64
-		isPublic  bool
-		createdAt time.Time
-		authorID  int
-		email     string
+		id          int
+		title       string
+		content     string
+		format      string
+		contentHTML string
+		excerpt     string
+		lat         *float64
+		lng         *float64
+		visibility  string
+		visible     bool
+		createdAt   time.Time
+		viewCount   int
+		status      string
+		published   *time.Time
+		revision    int
+		updatedAt   time.Time
+		tripID      *int
+		occurredAt  time.Time
+		tags        []string
+		verifiedAt  *time.Time
+		occurrence  *time.Time
+		engagement  journalEngagement
+		author      profileSummary
 	)
 
 	err := config.DB.QueryRow(
 		context.Background(),
 		query,
 		journalID,
-	).Scan(
-		&id,
-		&title,
-		&content,
-		&lat,
-		&lng,
-		&isPublic,
-		&createdAt,
-		&authorID,
-		&email,
-	)
+		userID,
+	).Scan(append(
+		append([]any{&id, &title, &content, &format, &contentHTML, &excerpt, &lat, &lng, &visibility, &visible, &createdAt, &viewCount, &status, &published, &revision, &updatedAt, &tripID, &occurredAt, &tags, &verifiedAt, &occurrence}, engagement.scanTargets()...),
+		&author.ID,
+		&author.Username,
+		&author.DisplayName,
+		&author.AvatarURL,
+	)...)
 
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "journal not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "journal not found")})
 		return
 	}
 
-	// 🔒 PRIVATE JOURNAL CHECK
-	if !isPublic {
-		if userID == nil || userID.(int) != authorID {
-			c.JSON(http.StatusForbidden, gin.H{"error": "this journal is private"})
-			return
-		}
+	// 🔒 VISIBILITY CHECK
+	if !visible {
+		c.JSON(http.StatusForbidden, gin.H{"error": tr(c, "this journal is private")})
+		return
 	}
-	if userID != nil {
-		bookmarkQuery := `
-		SELECT 1 FROM bookmarks
-		WHERE user_id = $1 AND journal_id = $2
-	`
-		err := config.DB.QueryRow(
-			context.Background(),
-			bookmarkQuery,
-			userID,
-			journalID,
-		).Scan(new(int))
-
-		bookmarked = err == nil
-	}
-	commentQuery := `
-	SELECT c.id, c.content, c.created_at, u.id, u.email
-	FROM comments c
-	JOIN users u ON u.id = c.user_id
-	WHERE c.journal_id = $1
-	ORDER BY c.created_at ASC
-`
 
-	rows, err := config.DB.Query(
-		context.Background(),
-		commentQuery,
-		journalID,
-	)
+	// view dari penulis sendiri tidak dihitung
+	if userID != author.ID {
+		go services.RecordJournalView(id)
+		viewCount++
+	}
+
+	comments, err := loadCommentTree(context.Background(), id, userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch comments"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch comments")})
 		return
 	}
-	defer rows.Close()
-
-	var comments []gin.H
-
-	for rows.Next() {
-		var (
-			commentID int
-			comment   string
-			created   time.Time
-			uid       int
-			email     string
-		)
 
-		rows.Scan(&commentID, &comment, &created, &uid, &email)
+	reactions, err := loadJournalReactions(context.Background(), id, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch reactions")})
+		return
+	}
 
-		comments = append(comments, gin.H{
-			"id":         commentID,
-			"content":    comment,
-			"created_at": created,
-			"user": gin.H{
-				"id":    uid,
-				"email": email,
-			},
-		})
+	images, err := loadJournalImages(context.Background(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch images")})
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusOK, engagement.into(gin.H{
 		"id":         id,
 		"title":      title,
 		"content":    content,
 		"latitude":   lat,
 		"longitude":  lng,
-		"is_public":  isPublic,
+		"is_public":  visibility == VisibilityPublic && status == StatusPublished,
+		"visibility": visibility,
 		"created_at": createdAt,
-		"author": gin.H{
-			"id":    authorID,
-			"email": email,
-		},
-		"bookmarked": bookmarked,
+		"view_count": viewCount,
+		"author":     author,
+		"bookmarked": engagement.BookmarkedByMe,
 		"comments":   comments,
+		"images":     images,
+
+		"status":       status,
+		"published_at": published,
+		"revision":     revision,
+		"updated_at":   updatedAt,
+
+		"trip_id":     tripID,
+		"occurred_at": occurredAt,
+		"tags":        tags,
+
+		"verified_attendee": verifiedAt != nil,
+		"verified_at":       verifiedAt,
+		"occurrence_start":  occurrence,
+
+		"content_format": format,
+		"content_html":   contentHTML,
+		"excerpt":        excerpt,
+
+		"reactions":    reactions.Counts,
+		"my_reactions": reactions.Mine,
+	}))
+}
+
+// UpdateJournalVisibility changes who can see a journal. Only the author
+// may change it.
+func UpdateJournalVisibility(c *gin.Context) {
+	var input struct {
+		Visibility string `json:"visibility" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil || !validVisibility(input.Visibility) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid visibility")})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 🔐 public journal wajib punya lokasi
+	query := `
+		UPDATE journals SET
+			visibility = $3,
+			revision = revision + 1,
+			updated_at = NOW()
+		WHERE id = $1 AND user_id = $2
+		  AND ($3 <> 'public' OR (latitude IS NOT NULL AND longitude IS NOT NULL))
+		RETURNING id
+	`
+
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to update journal")})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var id int
+	err = tx.QueryRow(ctx, query, c.Param("id"), c.GetInt("user_id"), input.Visibility).Scan(&id)
+	if err != nil {
+		var exists bool
+		_ = config.DB.QueryRow(
+			ctx,
+			`SELECT EXISTS (SELECT 1 FROM journals WHERE id = $1 AND user_id = $2)`,
+			c.Param("id"),
+			c.GetInt("user_id"),
+		).Scan(&exists)
+
+		if exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "public journal must have location")})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "journal not found")})
+		return
+	}
+
+	err = saveJournalRevision(ctx, tx, id, c.GetInt("user_id"))
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to update journal")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":         id,
+		"visibility": input.Visibility,
 	})
 }
 
@@ -353,6 +658,25 @@ func GetEventJournals(c *gin.Context) {
 
 	offset := (page - 1) * limit
 
+	tag, ok := tagQuery(c)
+	if !ok {
+		return
+	}
+
+	// ?verified=true → hanya yang check-in di lokasi event
+	verifiedOnly := c.Query("verified") == "true"
+
+	// ?occurrence_start= → hanya jurnal dari satu kejadian event berulang
+	var occurrenceStart *time.Time
+	if value := c.Query("occurrence_start"); value != "" {
+		start, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid occurrence_start")})
+			return
+		}
+		occurrenceStart = &start
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -360,25 +684,33 @@ func GetEventJournals(c *gin.Context) {
 	var total int
 	countQuery := `
 		SELECT COUNT(*)
-		FROM journals
-		WHERE event_id = $1
-		  AND is_public = true
+		FROM journals j
+		WHERE j.event_id = $1
+		  AND j.is_public = true
+		  AND ` + journalHasTagSQL("j", "$2") + `
+		  AND (NOT $3 OR j.verified_at IS NOT NULL)
+		  AND ($4::timestamptz IS NULL OR j.occurrence_start = $4)
 	`
-	_ = config.DB.QueryRow(ctx, countQuery, eventID).Scan(&total)
+	_ = config.DB.QueryRow(ctx, countQuery, eventID, tag, verifiedOnly, occurrenceStart).Scan(&total)
 
 	// 📄 data pagination
 	query := `
-		SELECT id, title, content, created_at
-		FROM journals
-		WHERE event_id = $1
-		  AND is_public = true
-		ORDER BY created_at DESC
+		SELECT j.id, j.title, j.content, j.created_at,
+		       ` + journalTagsSQL("j") + `, j.verified_at IS NOT NULL, j.occurrence_start,
+		       ` + engagementColumns("j", "$4") + `
+		FROM journals j
+		WHERE j.event_id = $1
+		  AND j.is_public = true
+		  AND ` + journalHasTagSQL("j", "$5") + `
+		  AND (NOT $6 OR j.verified_at IS NOT NULL)
+		  AND ($7::timestamptz IS NULL OR j.occurrence_start = $7)
+		ORDER BY j.created_at DESC
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := config.DB.Query(ctx, query, eventID, limit, offset)
+	rows, err := config.DB.Query(ctx, query, eventID, limit, offset, c.GetInt("user_id"), tag, verifiedOnly, occurrenceStart)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch journals"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch journals")})
 		return
 	}
 	defer rows.Close()
@@ -389,17 +721,25 @@ func GetEventJournals(c *gin.Context) {
 		var id int
 		var title, content string
 		var createdAt time.Time
+		var tags []string
+		var verified bool
+		var occurrence *time.Time
+		var engagement journalEngagement
 
-		if err := rows.Scan(&id, &title, &content, &createdAt); err != nil {
+		if err := rows.Scan(append([]any{&id, &title, &content, &createdAt, &tags, &verified, &occurrence}, engagement.scanTargets()...)...); err != nil {
 			continue
 		}
 
-		journals = append(journals, gin.H{
+		journals = append(journals, engagement.into(gin.H{
 			"id":         id,
 			"title":      title,
 			"content":    content,
 			"created_at": createdAt,
-		})
+			"tags":       tags,
+
+			"occurrence_start":  occurrence,
+			"verified_attendee": verified,
+		}))
 	}
 
 	totalPages := int(math.Ceil(float64(total) / float64(limit)))
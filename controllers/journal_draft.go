@@ -0,0 +1,449 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"event-journal-backend/config"
+	"event-journal-backend/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// resolvePublishStatus works out the status of a journal being created or
+// published: a publish_at in the future schedules it, anything else
+// publishes right away. Drafts can't carry a publish_at.
+func resolvePublishStatus(c *gin.Context, requested string, publishAt *time.Time) (string, bool) {
+	status, problem := publishStatus(requested, publishAt)
+	if problem != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, problem)})
+		return "", false
+	}
+	return status, true
+}
+
+// publishStatus is resolvePublishStatus without the response; problem is
+// the error message when the request is invalid.
+func publishStatus(requested string, publishAt *time.Time) (status, problem string) {
+	switch requested {
+	case "", StatusPublished, StatusScheduled:
+	case StatusDraft:
+		if publishAt != nil {
+			return "", "a draft cannot have publish_at"
+		}
+		return StatusDraft, ""
+	default:
+		return "", "invalid status"
+	}
+
+	if publishAt != nil && publishAt.After(time.Now()) {
+		return StatusScheduled, ""
+	}
+
+	return StatusPublished, ""
+}
+
+// announceJournal does the side effects of a journal going live.
+func announceJournal(journalID int, eventID *int) {
+	if eventID != nil {
+		go services.BumpEventPopularity(*eventID, services.PopularityJournal)
+	}
+	go services.NotifyJournalPublished(journalID)
+}
+
+// scheduledAt is the publish_at to store for status.
+func scheduledAt(status string, publishAt *time.Time) *time.Time {
+	if status == StatusScheduled {
+		return publishAt
+	}
+	return nil
+}
+
+// UpdateJournalInput is an autosave: only the fields sent are changed.
+// base_revision is the revision the client started editing from.
+type UpdateJournalInput struct {
+	BaseRevision  *int            `json:"base_revision" binding:"required"`
+	Title         *string         `json:"title"`
+	Content       *string         `json:"content"`
+	ContentFormat *string         `json:"content_format"`
+	Document      json.RawMessage `json:"document"`
+	Latitude      *float64        `json:"latitude"`
+	Longitude     *float64        `json:"longitude"`
+	Visibility    *string         `json:"visibility"`
+}
+
+// journalDraft is the editable state of a journal.
+type journalDraft struct {
+	ID            int
+	OwnerID       int
+	Title         string
+	Content       string
+	ContentFormat string
+	Latitude      *float64
+	Longitude     *float64
+	Visibility    string
+	Status        string
+	Revision      int
+	UpdatedAt     time.Time
+
+	// lokasi saat dimuat, untuk tahu perlu cek ulang check-in atau tidak
+	loadedLatitude  *float64
+	loadedLongitude *float64
+}
+
+func loadJournalDraftForUpdate(ctx context.Context, tx pgx.Tx, journalID string) (journalDraft, error) {
+	var d journalDraft
+
+	err := tx.QueryRow(
+		ctx,
+		`
+		SELECT id, user_id, title, content, content_format, latitude, longitude,
+		       visibility, status, revision, updated_at
+		FROM journals
+		WHERE id = $1
+		FOR UPDATE
+		`,
+		journalID,
+	).Scan(
+		&d.ID,
+		&d.OwnerID,
+		&d.Title,
+		&d.Content,
+		&d.ContentFormat,
+		&d.Latitude,
+		&d.Longitude,
+		&d.Visibility,
+		&d.Status,
+		&d.Revision,
+		&d.UpdatedAt,
+	)
+
+	d.loadedLatitude, d.loadedLongitude = d.Latitude, d.Longitude
+
+	return d, err
+}
+
+func (d journalDraft) hasLocation() bool {
+	return d.Latitude != nil && d.Longitude != nil && *d.Latitude != 0 && *d.Longitude != 0
+}
+
+func (d journalDraft) locationChanged() bool {
+	return !sameLocation(d.Latitude, d.loadedLatitude) || !sameLocation(d.Longitude, d.loadedLongitude)
+}
+
+// UpdateJournal autosaves changes to a journal. A stale base_revision is
+// refused with 409 so two devices don't silently overwrite each other.
+func UpdateJournal(c *gin.Context) {
+	var input UpdateJournalInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to update journal")})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	draft, err := loadJournalDraftForUpdate(ctx, tx, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "journal not found")})
+		return
+	}
+
+	if draft.OwnerID != c.GetInt("user_id") {
+		c.JSON(http.StatusForbidden, gin.H{"error": tr(c, "not allowed")})
+		return
+	}
+
+	if *input.BaseRevision != draft.Revision {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":      tr(c, "journal was changed elsewhere"),
+			"revision":   draft.Revision,
+			"updated_at": draft.UpdatedAt,
+		})
+		return
+	}
+
+	contentChanged := input.Content != nil || input.ContentFormat != nil || input.Document != nil
+
+	if input.Title != nil {
+		if *input.Title == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "title is required")})
+			return
+		}
+		draft.Title = *input.Title
+	}
+	if input.ContentFormat != nil {
+		if !services.ValidContentFormat(*input.ContentFormat) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid content_format")})
+			return
+		}
+		draft.ContentFormat = *input.ContentFormat
+	}
+	if input.Content != nil {
+		draft.Content = *input.Content
+	}
+	// dokumen blok disimpan apa adanya di kolom content
+	if draft.ContentFormat == services.ContentFormatBlocks && input.Document != nil {
+		draft.Content = string(input.Document)
+	}
+	if input.Latitude != nil {
+		draft.Latitude = input.Latitude
+	}
+	if input.Longitude != nil {
+		draft.Longitude = input.Longitude
+	}
+	if input.Visibility != nil {
+		if !validVisibility(*input.Visibility) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid visibility")})
+			return
+		}
+		draft.Visibility = *input.Visibility
+	}
+
+	// 🔐 public journal wajib punya lokasi (draft boleh menyusul)
+	if draft.Status != StatusDraft && draft.Visibility == VisibilityPublic && !draft.hasLocation() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "public journal must have location")})
+		return
+	}
+
+	saved, err := saveJournalDraft(ctx, tx, draft, c.GetInt("user_id"), contentChanged)
+	if errors.Is(err, services.ErrInvalidContent) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid content")})
+		return
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to update journal")})
+		return
+	}
+
+	c.JSON(http.StatusOK, saved.toJSON(draft, contentChanged))
+}
+
+// savedJournal is what saveJournalDraft wrote.
+type savedJournal struct {
+	Revision  int
+	UpdatedAt time.Time
+	Rendered  services.RenderedContent
+
+	// diisi kalau lokasi berubah dan check-in dicek ulang
+	CheckedIn  bool
+	VerifiedAt *time.Time
+}
+
+func (s savedJournal) toJSON(draft journalDraft, rendered bool) gin.H {
+	response := gin.H{
+		"id":         draft.ID,
+		"status":     draft.Status,
+		"revision":   s.Revision,
+		"updated_at": s.UpdatedAt,
+	}
+	if rendered {
+		response["content_html"] = s.Rendered.HTML
+		response["excerpt"] = s.Rendered.Excerpt
+	}
+	if s.CheckedIn {
+		response["verified_attendee"] = s.VerifiedAt != nil
+		response["verified_at"] = s.VerifiedAt
+	}
+	return response
+}
+
+// saveJournalDraft writes d back as a new revision of the journal,
+// re-rendering the content when it changed, and records the snapshot in
+// journal_revisions. A moved journal is checked in again. An unrenderable
+// document gives ErrInvalidContent.
+func saveJournalDraft(ctx context.Context, tx pgx.Tx, d journalDraft, editorID int, rerender bool) (savedJournal, error) {
+	var saved savedJournal
+
+	query := `
+		UPDATE journals SET
+			title = $2,
+			content = $3,
+			content_format = $4,
+			latitude = $5,
+			longitude = $6,
+			visibility = $7,
+			revision = revision + 1,
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING revision, updated_at
+	`
+
+	err := tx.QueryRow(
+		ctx,
+		query,
+		d.ID,
+		d.Title,
+		d.Content,
+		d.ContentFormat,
+		d.Latitude,
+		d.Longitude,
+		d.Visibility,
+	).Scan(&saved.Revision, &saved.UpdatedAt)
+	if err != nil {
+		return saved, err
+	}
+
+	if rerender {
+		saved.Rendered, err = renderJournalContent(d.ID, d.ContentFormat, d.Content)
+		if err != nil {
+			return saved, services.ErrInvalidContent
+		}
+
+		_, err = tx.Exec(
+			ctx,
+			`UPDATE journals SET content_html = $2, excerpt = $3 WHERE id = $1`,
+			d.ID,
+			saved.Rendered.HTML,
+			saved.Rendered.Excerpt,
+		)
+		if err != nil {
+			return saved, err
+		}
+	}
+
+	if d.locationChanged() {
+		saved.VerifiedAt, err = checkInJournal(ctx, tx, d.ID)
+		if err != nil {
+			return saved, err
+		}
+		saved.CheckedIn = true
+	}
+
+	return saved, saveJournalRevision(ctx, tx, d.ID, editorID)
+}
+
+// PublishJournal publishes a draft now, or schedules it when publish_at is
+// in the future. A scheduled journal can be rescheduled or published early
+// the same way.
+func PublishJournal(c *gin.Context) {
+	var input struct {
+		PublishAt *time.Time `json:"publish_at"`
+	}
+
+	// body boleh kosong → terbit sekarang
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	status, _ := resolvePublishStatus(c, StatusPublished, input.PublishAt)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to publish journal")})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	draft, err := loadJournalDraftForUpdate(ctx, tx, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "journal not found")})
+		return
+	}
+
+	if draft.OwnerID != c.GetInt("user_id") {
+		c.JSON(http.StatusForbidden, gin.H{"error": tr(c, "not allowed")})
+		return
+	}
+
+	if draft.Status == StatusPublished {
+		c.JSON(http.StatusConflict, gin.H{"error": tr(c, "journal is already published")})
+		return
+	}
+
+	// 🔐 public journal wajib punya lokasi
+	if draft.Visibility == VisibilityPublic && !draft.hasLocation() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "public journal must have location")})
+		return
+	}
+
+	eventID, publishedAt, err := publishJournalDraft(ctx, tx, draft.ID, status, input.PublishAt)
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to publish journal")})
+		return
+	}
+
+	if status == StatusPublished {
+		announceJournal(draft.ID, eventID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":           draft.ID,
+		"status":       status,
+		"publish_at":   scheduledAt(status, input.PublishAt),
+		"published_at": publishedAt,
+	})
+}
+
+// publishJournalDraft moves a draft or scheduled journal to status
+// (published or scheduled) inside tx.
+func publishJournalDraft(ctx context.Context, tx pgx.Tx, journalID int, status string, publishAt *time.Time) (*int, *time.Time, error) {
+	query := `
+		UPDATE journals SET
+			status = $2,
+			publish_at = $3,
+			published_at = CASE WHEN $2 = 'published' THEN NOW() END,
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING event_id, published_at
+	`
+
+	var eventID *int
+	var publishedAt *time.Time
+
+	err := tx.QueryRow(ctx, query, journalID, status, scheduledAt(status, publishAt)).Scan(&eventID, &publishedAt)
+
+	return eventID, publishedAt, err
+}
+
+// UnscheduleJournal takes a scheduled journal back to draft.
+func UnscheduleJournal(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := config.DB.Exec(
+		ctx,
+		`
+		UPDATE journals SET status = 'draft', publish_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND status = 'scheduled'
+		`,
+		c.Param("id"),
+		c.GetInt("user_id"),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to update journal")})
+		return
+	}
+
+	if result.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "scheduled journal not found")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "journal moved back to drafts",
+	})
+}
@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// journalEngagement is the counter block returned with every journal in
+// list and detail responses.
+type journalEngagement struct {
+	LikeCount      int
+	CommentCount   int
+	BookmarkCount  int
+	LikedByMe      bool
+	BookmarkedByMe bool
+}
+
+// engagementColumns selects the counters of journal alias plus whether the
+// viewer in userParam (0 for anonymous) liked or bookmarked it.
+func engagementColumns(alias, userParam string) string {
+	return alias + ".like_count, " + alias + ".comment_count, " + alias + ".bookmark_count, " +
+		"EXISTS (SELECT 1 FROM journal_likes ml WHERE ml.journal_id = " + alias + ".id AND ml.user_id = " + userParam + "), " +
+		"EXISTS (SELECT 1 FROM bookmarks mb WHERE mb.journal_id = " + alias + ".id AND mb.user_id = " + userParam + ")"
+}
+
+func (e *journalEngagement) scanTargets() []any {
+	return []any{&e.LikeCount, &e.CommentCount, &e.BookmarkCount, &e.LikedByMe, &e.BookmarkedByMe}
+}
+
+// into adds the counters to a journal response.
+func (e journalEngagement) into(journal gin.H) gin.H {
+	journal["like_count"] = e.LikeCount
+	journal["comment_count"] = e.CommentCount
+	journal["bookmark_count"] = e.BookmarkCount
+	journal["liked_by_me"] = e.LikedByMe
+	journal["bookmarked_by_me"] = e.BookmarkedByMe
+
+	return journal
+}
+
+// adjustJournalCounter moves one of the counter columns by delta inside tx
+// and returns the new value.
+func adjustJournalCounter(ctx context.Context, tx pgx.Tx, journalID any, column string, delta int) (int, error) {
+	var count int
+	err := tx.QueryRow(
+		ctx,
+		`UPDATE journals SET `+column+` = GREATEST(`+column+` + $2, 0) WHERE id = $1 RETURNING `+column,
+		journalID,
+		delta,
+	).Scan(&count)
+
+	return count, err
+}
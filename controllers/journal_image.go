@@ -2,55 +2,211 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"event-journal-backend/config"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
+const journalImageDir = "uploads/journals"
+
+// journalImageURL is the guarded URL clients use for a journal image; the
+// files themselves are not served statically.
+func journalImageURL(journalID, imageID int) string {
+	return fmt.Sprintf("/api/journals/%d/images/%d", journalID, imageID)
+}
+
+func loadJournalImages(ctx context.Context, journalID int) ([]gin.H, error) {
+	rows, err := config.DB.Query(
+		ctx,
+		`SELECT id FROM journal_images WHERE journal_id = $1 AND image_url IS NOT NULL ORDER BY id ASC`,
+		journalID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	images := []gin.H{}
+
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		images = append(images, gin.H{
+			"id":        id,
+			"image_url": journalImageURL(journalID, id),
+		})
+	}
+
+	return images, rows.Err()
+}
+
 func UploadJournalImage(c *gin.Context) {
 	journalID := c.Param("id")
+	userID := c.GetInt("user_id")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var ownerID, id int
+	err := config.DB.QueryRow(ctx, `SELECT id, user_id FROM journals WHERE id = $1`, journalID).Scan(&id, &ownerID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "journal not found")})
+		return
+	}
+
+	if ownerID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": tr(c, "not allowed")})
+		return
+	}
 
 	file, err := c.FormFile("image")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "image is required"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "image is required")})
 		return
 	}
 
-	// generate filename
+	// client_id: file untuk gambar yang sudah dicatat lewat sync
+	var clientID *string
+	if value := c.PostForm("client_id"); value != "" {
+		if !validClientID(value) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid client_id")})
+			return
+		}
+		clientID = &value
+	}
+
+	// unggahan ulang dengan client_id yang sama tidak menyimpan file lagi
+	if imageID, ok := uploadedClientImage(ctx, id, clientID); ok {
+		c.JSON(http.StatusOK, gin.H{
+			"id":        imageID,
+			"image_url": journalImageURL(id, imageID),
+		})
+		return
+	}
+
+	// nama file unik per request, jadi unggahan yang kalah race hanya
+	// menghapus file miliknya sendiri
 	filename := fmt.Sprintf(
-		"journal_%s_%d%s",
-		journalID,
-		time.Now().Unix(),
+		"journal_%d_%s%s",
+		id,
+		uuid.NewString(),
 		filepath.Ext(file.Filename),
 	)
 
-	savePath := "uploads/journals/" + filename
+	savePath := journalImageDir + "/" + filename
 	if err := c.SaveUploadedFile(file, savePath); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save image"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to save image")})
 		return
 	}
 
-	imageURL := "/uploads/journals/" + filename
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
+	// baris dari sync hanya diisi sekali; kalau sudah terisi RETURNING kosong
 	query := `
-		INSERT INTO journal_images (journal_id, image_url)
-		VALUES ($1, $2)
+		INSERT INTO journal_images (journal_id, image_url, client_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (journal_id, client_id) DO UPDATE SET image_url = EXCLUDED.image_url
+		WHERE journal_images.image_url IS NULL
+		RETURNING id
 	`
-	_, err = config.DB.Exec(ctx, query, journalID, imageURL)
+
+	var imageID int
+	err = config.DB.QueryRow(ctx, query, id, "/"+savePath, clientID).Scan(&imageID)
+	if err != nil {
+		// file ini belum dirujuk baris mana pun
+		os.Remove(savePath)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		// unggahan lain dengan client_id yang sama menang duluan
+		if imageID, ok := uploadedClientImage(ctx, id, clientID); ok {
+			c.JSON(http.StatusOK, gin.H{
+				"id":        imageID,
+				"image_url": journalImageURL(id, imageID),
+			})
+			return
+		}
+	}
+	if err == nil {
+		_, err = config.DB.Exec(ctx, `UPDATE journals SET updated_at = NOW() WHERE id = $1`, id)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save image record"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to save image record")})
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
-		"image_url": imageURL,
+		"id":        imageID,
+		"image_url": journalImageURL(id, imageID),
 	})
 }
+
+// uploadedClientImage finds the image of journalID registered with
+// clientID whose file was already uploaded, so a retried upload can
+// return it instead of storing a second file.
+func uploadedClientImage(ctx context.Context, journalID int, clientID *string) (int, bool) {
+	if clientID == nil {
+		return 0, false
+	}
+
+	var imageID int
+	err := config.DB.QueryRow(
+		ctx,
+		`SELECT id FROM journal_images WHERE journal_id = $1 AND client_id = $2 AND image_url IS NOT NULL`,
+		journalID,
+		*clientID,
+	).Scan(&imageID)
+
+	return imageID, err == nil
+}
+
+// ServeJournalImage streams an image file after the same visibility check
+// as the journal itself.
+func ServeJournalImage(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	journal, ok := findVisibleJournal(ctx, c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	serveJournalImageFile(ctx, c, journal, c.Param("image_id"))
+}
+
+func serveJournalImageFile(ctx context.Context, c *gin.Context, journal journalAccess, imageID string) {
+	var imageURL string
+	err := config.DB.QueryRow(
+		ctx,
+		`SELECT image_url FROM journal_images WHERE id = $1 AND journal_id = $2`,
+		imageID,
+		journal.ID,
+	).Scan(&imageURL)
+
+	// jangan sampai path di DB keluar dari folder upload jurnal
+	path := filepath.Clean(strings.TrimPrefix(imageURL, "/"))
+	if err != nil || !strings.HasPrefix(path, journalImageDir+string(filepath.Separator)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "image not found")})
+		return
+	}
+
+	// draft dan jurnal terjadwal hanya untuk penulisnya, jangan sampai
+	// tersimpan di cache bersama
+	if journal.Status == StatusPublished && journal.Visibility == VisibilityPublic {
+		c.Header("Cache-Control", "public, max-age=86400")
+	} else {
+		c.Header("Cache-Control", "private, no-store")
+	}
+
+	c.File(path)
+}
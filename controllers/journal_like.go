@@ -2,77 +2,187 @@ package controllers
 
 import (
 	"context"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"event-journal-backend/config"
+	"event-journal-backend/services"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 )
 
-func ToggleJournalLike(c *gin.Context) {
+func LikeJournal(c *gin.Context) {
+	setJournalLike(c, true)
+}
+
+func UnlikeJournal(c *gin.Context) {
+	setJournalLike(c, false)
+}
+
+// setJournalLike is idempotent: liking twice or unliking a journal that was
+// never liked succeeds and simply returns the current count.
+func setJournalLike(c *gin.Context, liked bool) {
 	journalID := c.Param("id")
-	userID, _ := c.Get("user_id")
+	userID := c.GetInt("user_id")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	// cek sudah like atau belum
-	var exists bool
-	checkQuery := `
-		SELECT EXISTS (
-			SELECT 1 FROM journal_likes
-			WHERE user_id = $1 AND journal_id = $2
-		)
-	`
-	_ = config.DB.QueryRow(ctx, checkQuery, userID, journalID).Scan(&exists)
+	journal, ok := findVisibleJournal(ctx, c, journalID)
+	if !ok {
+		return
+	}
 
-	if exists {
-		// UNLIKE
-		deleteQuery := `
-			DELETE FROM journal_likes
-			WHERE user_id = $1 AND journal_id = $2
-		`
-		_, _ = config.DB.Exec(ctx, deleteQuery, userID, journalID)
+	failed := "failed to like journal"
+	if !liked {
+		failed = "failed to unlike journal"
+	}
 
-		c.JSON(http.StatusOK, gin.H{
-			"liked": false,
-		})
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, failed)})
 		return
 	}
+	defer tx.Rollback(ctx)
 
-	// LIKE
-	insertQuery := `
-		INSERT INTO journal_likes (user_id, journal_id)
-		VALUES ($1, $2)
-	`
-	_, err := config.DB.Exec(ctx, insertQuery, userID, journalID)
+	changed, likeCount, err := toggleJournalLike(ctx, tx, userID, journal.ID, liked)
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to like journal"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, failed)})
 		return
 	}
 
+	if changed {
+		bumpLikePopularity(journal.ID, liked)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"liked": true,
+		"liked":      liked,
+		"like_count": likeCount,
 	})
 }
 
+// toggleJournalLike likes or unlikes journalID for userID inside tx,
+// keeping like_count and the sync tombstones in step. It reports whether
+// anything changed and returns the new like count.
+func toggleJournalLike(ctx context.Context, tx pgx.Tx, userID, journalID int, liked bool) (bool, int, error) {
+	query := `
+		INSERT INTO journal_likes (user_id, journal_id)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, journal_id) DO NOTHING
+	`
+	delta := 1
+	if !liked {
+		query = `
+			DELETE FROM journal_likes
+			WHERE user_id = $1 AND journal_id = $2
+		`
+		delta = -1
+	}
+
+	result, err := tx.Exec(ctx, query, userID, journalID)
+	if err != nil {
+		return false, 0, err
+	}
+
+	changed := result.RowsAffected() > 0
+	if !changed {
+		delta = 0
+	}
+
+	if changed {
+		err = setSyncTombstone(ctx, tx, userID, tombstoneLike, journalID, !liked)
+	}
+
+	likeCount := 0
+	if err == nil {
+		likeCount, err = adjustJournalCounter(ctx, tx, journalID, "like_count", delta)
+	}
+
+	return changed, likeCount, err
+}
+
+func bumpLikePopularity(journalID int, liked bool) {
+	delta := services.PopularityLike
+	if !liked {
+		delta = -delta
+	}
+	go services.BumpJournalPopularity(journalID, delta)
+}
+
 func GetJournalLikes(c *gin.Context) {
 	journalID := c.Param("id")
 
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 50 {
+		limit = 20
+	}
+
+	offset := (page - 1) * limit
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	journal, ok := findVisibleJournal(ctx, c, journalID)
+	if !ok {
+		return
+	}
+
 	var total int
+	_ = config.DB.QueryRow(ctx, `SELECT like_count FROM journals WHERE id = $1`, journal.ID).Scan(&total)
+
 	query := `
-		SELECT COUNT(*)
-		FROM journal_likes
-		WHERE journal_id = $1
+		SELECT ` + profileSummaryColumns("u") + `, l.created_at
+		FROM journal_likes l
+		JOIN users u ON u.id = l.user_id
+		WHERE l.journal_id = $1
+		ORDER BY l.created_at DESC, u.id DESC
+		LIMIT $2 OFFSET $3
 	`
-	_ = config.DB.QueryRow(ctx, query, journalID).Scan(&total)
+
+	rows, err := config.DB.Query(ctx, query, journal.ID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch likes")})
+		return
+	}
+	defer rows.Close()
+
+	var likes []gin.H
+
+	for rows.Next() {
+		var user profileSummary
+		var likedAt time.Time
+
+		if err := rows.Scan(&user.ID, &user.Username, &user.DisplayName, &user.AvatarURL, &likedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch likes")})
+			return
+		}
+
+		likes = append(likes, gin.H{
+			"user":     user,
+			"liked_at": likedAt,
+		})
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"journal_id":  journalID,
+		"journal_id":  journal.ID,
 		"total_likes": total,
+		"data":        likes,
+		"pagination": gin.H{
+			"page":        page,
+			"limit":       limit,
+			"total":       total,
+			"total_pages": int(math.Ceil(float64(total) / float64(limit))),
+		},
 	})
 }
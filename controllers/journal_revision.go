@@ -0,0 +1,312 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"event-journal-backend/config"
+	"event-journal-backend/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// saveJournalRevision snapshots the journal as it is now in tx. Call it
+// after every change that bumps journals.revision.
+func saveJournalRevision(ctx context.Context, tx pgx.Tx, journalID, editorID int) error {
+	_, err := tx.Exec(
+		ctx,
+		`
+		INSERT INTO journal_revisions (
+			journal_id, revision, title, content, content_format,
+			latitude, longitude, visibility, edited_by
+		)
+		SELECT id, revision, title, COALESCE(content, ''), content_format,
+		       latitude, longitude, visibility, $2
+		FROM journals
+		WHERE id = $1
+		ON CONFLICT (journal_id, revision) DO NOTHING
+		`,
+		journalID,
+		editorID,
+	)
+	return err
+}
+
+type journalRevision struct {
+	Revision      int       `json:"revision"`
+	Title         string    `json:"title"`
+	Content       string    `json:"content"`
+	ContentFormat string    `json:"content_format"`
+	Latitude      *float64  `json:"latitude"`
+	Longitude     *float64  `json:"longitude"`
+	Visibility    string    `json:"visibility"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func findJournalRevision(ctx context.Context, journalID int, revision string) (journalRevision, error) {
+	var r journalRevision
+
+	err := config.DB.QueryRow(
+		ctx,
+		`
+		SELECT revision, title, content, content_format, latitude, longitude, visibility, created_at
+		FROM journal_revisions
+		WHERE journal_id = $1 AND revision = $2
+		`,
+		journalID,
+		revision,
+	).Scan(&r.Revision, &r.Title, &r.Content, &r.ContentFormat, &r.Latitude, &r.Longitude, &r.Visibility, &r.CreatedAt)
+
+	return r, err
+}
+
+func sameLocation(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+//
+// ===== REVISION HISTORY (AUTHOR) =====
+//
+
+func GetJournalRevisions(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 50 {
+		limit = 20
+	}
+
+	offset := (page - 1) * limit
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	journalID, ok := ownJournal(ctx, c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var total int
+	_ = config.DB.QueryRow(ctx, `SELECT COUNT(*) FROM journal_revisions WHERE journal_id = $1`, journalID).Scan(&total)
+
+	query := `
+		SELECT revision, title, visibility, latitude, longitude,
+		       CHAR_LENGTH(content), created_at
+		FROM journal_revisions
+		WHERE journal_id = $1
+		ORDER BY revision DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := config.DB.Query(ctx, query, journalID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch revisions")})
+		return
+	}
+	defer rows.Close()
+
+	var revisions []gin.H
+
+	for rows.Next() {
+		var (
+			revision   int
+			title      string
+			visibility string
+			lat, lng   *float64
+			length     int
+			createdAt  time.Time
+		)
+
+		if err := rows.Scan(&revision, &title, &visibility, &lat, &lng, &length, &createdAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch revisions")})
+			return
+		}
+
+		revisions = append(revisions, gin.H{
+			"revision":       revision,
+			"title":          title,
+			"visibility":     visibility,
+			"latitude":       lat,
+			"longitude":      lng,
+			"content_length": length,
+			"created_at":     createdAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": revisions,
+		"pagination": gin.H{
+			"page":        page,
+			"limit":       limit,
+			"total":       total,
+			"total_pages": int(math.Ceil(float64(total) / float64(limit))),
+		},
+	})
+}
+
+func GetJournalRevision(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	journalID, ok := ownJournal(ctx, c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	revision, err := findJournalRevision(ctx, journalID, c.Param("revision"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "revision not found")})
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+// DiffJournalRevisions compares ?from=<revision> with ?to=<revision>
+// (default: the latest). Content is diffed line by line.
+func DiffJournalRevisions(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	journalID, ok := ownJournal(ctx, c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	to := c.Query("to")
+	if to == "" {
+		var latest int
+		_ = config.DB.QueryRow(ctx, `SELECT revision FROM journals WHERE id = $1`, journalID).Scan(&latest)
+		to = strconv.Itoa(latest)
+	}
+
+	from, err := findJournalRevision(ctx, journalID, c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "revision not found")})
+		return
+	}
+
+	target, err := findJournalRevision(ctx, journalID, to)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "revision not found")})
+		return
+	}
+
+	changes := gin.H{}
+	if from.Title != target.Title {
+		changes["title"] = gin.H{"from": from.Title, "to": target.Title}
+	}
+	if from.Visibility != target.Visibility {
+		changes["visibility"] = gin.H{"from": from.Visibility, "to": target.Visibility}
+	}
+	if from.ContentFormat != target.ContentFormat {
+		changes["content_format"] = gin.H{"from": from.ContentFormat, "to": target.ContentFormat}
+	}
+	if !sameLocation(from.Latitude, target.Latitude) || !sameLocation(from.Longitude, target.Longitude) {
+		changes["location"] = gin.H{
+			"from": gin.H{"latitude": from.Latitude, "longitude": from.Longitude},
+			"to":   gin.H{"latitude": target.Latitude, "longitude": target.Longitude},
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":    from.Revision,
+		"to":      target.Revision,
+		"changes": changes,
+		"content": services.DiffLines(from.Content, target.Content),
+	})
+}
+
+// RestoreJournalRevision brings an old revision back as a new revision, so
+// the history itself is never rewritten.
+func RestoreJournalRevision(c *gin.Context) {
+	var input struct {
+		BaseRevision *int `json:"base_revision"`
+	}
+
+	// body boleh kosong
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to restore revision")})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	draft, err := loadJournalDraftForUpdate(ctx, tx, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "journal not found")})
+		return
+	}
+
+	if draft.OwnerID != c.GetInt("user_id") {
+		c.JSON(http.StatusForbidden, gin.H{"error": tr(c, "not allowed")})
+		return
+	}
+
+	if input.BaseRevision != nil && *input.BaseRevision != draft.Revision {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":      tr(c, "journal was changed elsewhere"),
+			"revision":   draft.Revision,
+			"updated_at": draft.UpdatedAt,
+		})
+		return
+	}
+
+	revision, err := findJournalRevision(ctx, draft.ID, c.Param("revision"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "revision not found")})
+		return
+	}
+
+	draft.Title = revision.Title
+	draft.Content = revision.Content
+	draft.ContentFormat = revision.ContentFormat
+	draft.Latitude = revision.Latitude
+	draft.Longitude = revision.Longitude
+	draft.Visibility = revision.Visibility
+
+	// 🔐 public journal wajib punya lokasi
+	if draft.Status != StatusDraft && draft.Visibility == VisibilityPublic && !draft.hasLocation() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "public journal must have location")})
+		return
+	}
+
+	saved, err := saveJournalDraft(ctx, tx, draft, c.GetInt("user_id"), true)
+	if errors.Is(err, services.ErrInvalidContent) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid content")})
+		return
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to restore revision")})
+		return
+	}
+
+	response := saved.toJSON(draft, true)
+	response["restored_from"] = revision.Revision
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,1046 @@
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"event-journal-backend/config"
+	"event-journal-backend/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	maxSyncBatch = 100
+
+	// syncJournalPage is how many journals one sync response downloads;
+	// the rest follow on the next call with the returned token.
+	syncJournalPage = 100
+
+	// syncOverlap is subtracted from the sync token so rows committed
+	// just after the previous sync read its clock are not missed. The
+	// overlap means a few rows can be sent twice; applying them is
+	// idempotent on the client.
+	syncOverlap = 5 * time.Second
+
+	tombstoneLike     = "like"
+	tombstoneBookmark = "bookmark"
+)
+
+// Result statuses of uploaded items.
+const (
+	syncCreated   = "created"
+	syncUpdated   = "updated"
+	syncUnchanged = "unchanged"
+	syncConflict  = "conflict"
+	syncRejected  = "rejected"
+)
+
+// setSyncTombstone records (deleted) or clears the deletion of a like or
+// bookmark so the user's other devices learn about it on their next sync.
+func setSyncTombstone(ctx context.Context, tx pgx.Tx, userID int, kind string, journalID int, deleted bool) error {
+	query := `DELETE FROM sync_tombstones WHERE user_id = $1 AND kind = $2 AND journal_id = $3`
+	if deleted {
+		query = `
+			INSERT INTO sync_tombstones (user_id, kind, journal_id)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (user_id, kind, journal_id) DO UPDATE SET deleted_at = NOW()
+		`
+	}
+
+	_, err := tx.Exec(ctx, query, userID, kind, journalID)
+	return err
+}
+
+// syncPosition is where a download stands. A finished download only
+// has SyncedAt, the database clock when it was read. A download cut into
+// pages also remembers where it started from (Since, nil for a full
+// download) and the (updated_at, id) of the last journal sent.
+type syncPosition struct {
+	SyncedAt time.Time
+	Since    *time.Time
+
+	Paging       bool
+	AfterUpdated time.Time
+	AfterID      int
+}
+
+// Sync tokens are opaque to clients: base64("v1:<unix nanos>") for a
+// finished download, base64("v2:<synced>:<since or ->:<updated micros>:<id>")
+// for one with more pages to come.
+func encodeSyncToken(p syncPosition) string {
+	raw := "v1:" + strconv.FormatInt(p.SyncedAt.UnixNano(), 10)
+
+	if p.Paging {
+		since := "-"
+		if p.Since != nil {
+			since = strconv.FormatInt(p.Since.UnixNano(), 10)
+		}
+		raw = "v2:" + strconv.FormatInt(p.SyncedAt.UnixNano(), 10) + ":" + since + ":" +
+			strconv.FormatInt(p.AfterUpdated.UnixMicro(), 10) + ":" + strconv.Itoa(p.AfterID)
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeSyncToken(token string) (syncPosition, error) {
+	var p syncPosition
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return p, err
+	}
+
+	version, rest, _ := strings.Cut(string(raw), ":")
+	parts := strings.Split(rest, ":")
+
+	switch {
+	case version == "v1" && len(parts) == 1:
+	case version == "v2" && len(parts) == 4:
+		p.Paging = true
+	default:
+		return p, errors.New("malformed sync token")
+	}
+
+	n, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return p, err
+	}
+	p.SyncedAt = time.Unix(0, n)
+
+	if !p.Paging {
+		// token selesai: unduhan berikutnya mulai dari jam itu
+		since := p.SyncedAt
+		p.Since = &since
+		return p, nil
+	}
+
+	if parts[1] != "-" {
+		n, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return p, err
+		}
+		since := time.Unix(0, n)
+		p.Since = &since
+	}
+
+	micros, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return p, err
+	}
+	p.AfterUpdated = time.UnixMicro(micros)
+
+	p.AfterID, err = strconv.Atoi(parts[3])
+	return p, err
+}
+
+// SyncJournal is the full offline copy of one of the user's journals.
+// New journals are identified by client_id, journals created online by
+// id. base_revision is the server revision the offline edit started from;
+// it may be left out for a journal the client created itself. occurred_at
+// is when the journal was written on the device; it dates the journal and
+// decides the event check-in, so a journal written offline at an event
+// still earns the verified-attendee badge when it is uploaded later.
+type SyncJournal struct {
+	ClientID      *string         `json:"client_id"`
+	ID            *int            `json:"id"`
+	BaseRevision  *int            `json:"base_revision"`
+	EventID       *int            `json:"event_id"`
+	Title         string          `json:"title"`
+	Content       string          `json:"content"`
+	ContentFormat string          `json:"content_format"`
+	Document      json.RawMessage `json:"document"`
+	Latitude      *float64        `json:"latitude"`
+	Longitude     *float64        `json:"longitude"`
+	Visibility    string          `json:"visibility"`
+	Status        string          `json:"status"`     // draft | published, kosong = tetap
+	PublishAt     *time.Time      `json:"publish_at"` // di masa depan → dijadwalkan
+	Tags          []string        `json:"tags"`       // nil = tag tidak diubah
+
+	// hanya dipakai saat jurnal dibuat
+	OccurrenceStart *time.Time `json:"occurrence_start"`
+	OccurredAt      *time.Time `json:"occurred_at"` // waktu ditulis di perangkat
+}
+
+// syncJournalRef points at a journal either by server id or by the
+// client_id of one of the user's own journals.
+type syncJournalRef struct {
+	JournalID       *int    `json:"journal_id"`
+	JournalClientID *string `json:"journal_client_id"`
+}
+
+// SyncImage registers an image taken offline. The file is uploaded later
+// to POST /journals/:id/images with the same client_id.
+type SyncImage struct {
+	ClientID string `json:"client_id"`
+	syncJournalRef
+}
+
+type SyncLike struct {
+	syncJournalRef
+	Liked bool `json:"liked"`
+}
+
+type SyncBookmark struct {
+	syncJournalRef
+	Deleted      bool    `json:"deleted"`
+	CollectionID *int    `json:"collection_id"`
+	Note         *string `json:"note"`
+}
+
+type SyncInput struct {
+	SyncToken string         `json:"sync_token"`
+	Journals  []SyncJournal  `json:"journals"`
+	Images    []SyncImage    `json:"images"`
+	Likes     []SyncLike     `json:"likes"`
+	Bookmarks []SyncBookmark `json:"bookmarks"`
+}
+
+// Sync uploads what the app recorded offline and downloads what changed
+// for the user since sync_token. Every uploaded item is applied on its
+// own and gets its own result, so one bad item never fails the batch, and
+// re-sending a batch after a lost response is safe.
+//
+// The download is paged: while has_more is true the app calls again with
+// the returned sync_token (uploads may be empty) until the last page,
+// whose token starts the next sync.
+//
+// Journal edits carry the revision they were based on. When the server
+// copy moved on in the meantime the edit is not applied: the result is a
+// conflict holding the server copy, and the app resends its merge with
+// the new base_revision.
+func Sync(c *gin.Context) {
+	var input SyncInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(input.Journals) > maxSyncBatch || len(input.Images) > maxSyncBatch ||
+		len(input.Likes) > maxSyncBatch || len(input.Bookmarks) > maxSyncBatch {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "too many items in one sync")})
+		return
+	}
+
+	var position syncPosition
+	if input.SyncToken != "" {
+		p, err := decodeSyncToken(input.SyncToken)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid sync token")})
+			return
+		}
+		position = p
+	}
+
+	// token lebih tua dari tombstone → unduh ulang semuanya
+	if !position.Paging && position.Since != nil && time.Since(*position.Since) > services.SyncTombstoneRetention() {
+		position.Since = nil
+	}
+	full := position.Since == nil
+
+	userID := c.GetInt("user_id")
+
+	// journals dulu, supaya gambar/like/bookmark bisa merujuk client_id-nya
+	results := gin.H{}
+
+	journalResults := []gin.H{}
+	for _, item := range input.Journals {
+		journalResults = append(journalResults, syncJournal(c, userID, item))
+	}
+	results["journals"] = journalResults
+
+	imageResults := []gin.H{}
+	for _, item := range input.Images {
+		imageResults = append(imageResults, syncImage(c, userID, item))
+	}
+	results["images"] = imageResults
+
+	likeResults := []gin.H{}
+	for _, item := range input.Likes {
+		likeResults = append(likeResults, syncLike(c, userID, item))
+	}
+	results["likes"] = likeResults
+
+	bookmarkResults := []gin.H{}
+	for _, item := range input.Bookmarks {
+		bookmarkResults = append(bookmarkResults, syncBookmark(c, userID, item))
+	}
+	results["bookmarks"] = bookmarkResults
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// halaman pertama: jam DB dibaca sebelum perubahan diambil, dan
+	// halaman berikutnya tetap memakai jam itu
+	if !position.Paging {
+		if err := config.DB.QueryRow(ctx, `SELECT NOW()`).Scan(&position.SyncedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to sync")})
+			return
+		}
+		position.AfterUpdated = time.Time{}
+		if position.Since != nil {
+			position.AfterUpdated = position.Since.Add(-syncOverlap)
+		}
+		position.AfterID = 0
+	}
+
+	changes, next, err := loadSyncChanges(ctx, userID, position)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to sync")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results":    results,
+		"changes":    changes,
+		"full":       full,
+		"has_more":   next.Paging,
+		"sync_token": encodeSyncToken(next),
+	})
+}
+
+// syncRejection is the result of an item that could not be applied.
+func syncRejection(c *gin.Context, result gin.H, message string) gin.H {
+	result["status"] = syncRejected
+	result["error"] = tr(c, message)
+	return result
+}
+
+func validClientID(id string) bool {
+	_, err := uuid.Parse(id)
+	return err == nil
+}
+
+// resolveSyncJournal finds the journal a like, bookmark or image points
+// at. Journals referenced by id must be visible to the user; by
+// client_id, they must be the user's own.
+func resolveSyncJournal(ctx context.Context, userID int, ref syncJournalRef) (int, bool) {
+	var journalID int
+	var err error
+
+	switch {
+	case ref.JournalID != nil:
+		err = config.DB.QueryRow(
+			ctx,
+			`SELECT j.id FROM journals j WHERE j.id = $1 AND `+journalVisibleSQL("j", "$2"),
+			*ref.JournalID,
+			userID,
+		).Scan(&journalID)
+	case ref.JournalClientID != nil && validClientID(*ref.JournalClientID):
+		err = config.DB.QueryRow(
+			ctx,
+			`SELECT id FROM journals WHERE user_id = $1 AND client_id = $2`,
+			userID,
+			*ref.JournalClientID,
+		).Scan(&journalID)
+	default:
+		return 0, false
+	}
+
+	return journalID, err == nil
+}
+
+func (ref syncJournalRef) result() gin.H {
+	return gin.H{
+		"journal_id":        ref.JournalID,
+		"journal_client_id": ref.JournalClientID,
+	}
+}
+
+//
+// ===== SYNC: JOURNALS =====
+//
+
+// validateSyncJournal normalizes item the way CreateJournal does and
+// returns the error message when it is invalid.
+func validateSyncJournal(item SyncJournal) (SyncJournal, string) {
+	v := item
+
+	if item.ClientID == nil && item.ID == nil {
+		return v, "client_id or id is required"
+	}
+	if item.ClientID != nil && !validClientID(*item.ClientID) {
+		return v, "invalid client_id"
+	}
+	if strings.TrimSpace(item.Title) == "" {
+		return v, "title is required"
+	}
+
+	if item.OccurredAt != nil {
+		occurredAt, problem := checkOccurredAt(*item.OccurredAt, time.Now())
+		if problem != "" {
+			return v, problem
+		}
+		v.OccurredAt = &occurredAt
+	}
+
+	if v.Visibility == "" {
+		v.Visibility = VisibilityPrivate
+	}
+	if !validVisibility(v.Visibility) {
+		return v, "invalid visibility"
+	}
+
+	if v.ContentFormat == "" {
+		v.ContentFormat = services.ContentFormatPlain
+	}
+	if !services.ValidContentFormat(v.ContentFormat) {
+		return v, "invalid content_format"
+	}
+
+	// dokumen blok disimpan apa adanya di kolom content
+	if v.ContentFormat == services.ContentFormatBlocks {
+		v.Content = string(item.Document)
+	}
+
+	switch item.Status {
+	case "", StatusDraft, StatusPublished, StatusScheduled:
+	default:
+		return v, "invalid status"
+	}
+
+	if item.Tags != nil {
+		tags, err := services.NormalizeTags(item.Tags)
+		if errors.Is(err, services.ErrTooManyTags) {
+			return v, "a journal can have at most 10 tags"
+		}
+		if err != nil {
+			return v, "invalid tag"
+		}
+		v.Tags = tags
+	}
+
+	return v, ""
+}
+
+// matches reports whether the server copy already has the item's content,
+// which makes a resent edit a no-op instead of a conflict.
+func (item SyncJournal) matches(d journalDraft) bool {
+	return item.Title == d.Title &&
+		item.Content == d.Content &&
+		item.ContentFormat == d.ContentFormat &&
+		item.Visibility == d.Visibility &&
+		sameLocation(item.Latitude, d.Latitude) &&
+		sameLocation(item.Longitude, d.Longitude)
+}
+
+func syncJournalServerCopy(d journalDraft) gin.H {
+	return gin.H{
+		"id":             d.ID,
+		"title":          d.Title,
+		"content":        d.Content,
+		"content_format": d.ContentFormat,
+		"latitude":       d.Latitude,
+		"longitude":      d.Longitude,
+		"visibility":     d.Visibility,
+		"status":         d.Status,
+		"revision":       d.Revision,
+		"updated_at":     d.UpdatedAt,
+	}
+}
+
+func syncJournal(c *gin.Context, userID int, input SyncJournal) gin.H {
+	result := gin.H{
+		"client_id": input.ClientID,
+		"id":        input.ID,
+	}
+
+	item, problem := validateSyncJournal(input)
+	if problem != "" {
+		return syncRejection(c, result, problem)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return syncRejection(c, result, "failed to sync")
+	}
+	defer tx.Rollback(ctx)
+
+	var journalID int
+	if item.ID != nil {
+		err = tx.QueryRow(ctx, `SELECT id FROM journals WHERE id = $1 AND user_id = $2`, *item.ID, userID).Scan(&journalID)
+		if err != nil {
+			return syncRejection(c, result, "journal not found")
+		}
+	} else {
+		err = tx.QueryRow(
+			ctx,
+			`SELECT id FROM journals WHERE user_id = $1 AND client_id = $2`,
+			userID,
+			*item.ClientID,
+		).Scan(&journalID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return createSyncJournal(ctx, tx, c, userID, item, result)
+		}
+		if err != nil {
+			return syncRejection(c, result, "failed to sync")
+		}
+	}
+
+	draft, err := loadJournalDraftForUpdate(ctx, tx, strconv.Itoa(journalID))
+	if err != nil {
+		return syncRejection(c, result, "journal not found")
+	}
+	result["id"] = draft.ID
+
+	// tanpa base_revision: edit atas jurnal yang dibuat app ini sendiri
+	base := 1
+	if item.BaseRevision != nil {
+		base = *item.BaseRevision
+	}
+
+	edited := !item.matches(draft)
+
+	// isi yang sama dengan revisi lama = kirim ulang, bukan konflik
+	if base != draft.Revision && edited {
+		result["status"] = syncConflict
+		result["error"] = tr(c, "journal was changed elsewhere")
+		result["server"] = syncJournalServerCopy(draft)
+		return result
+	}
+
+	publish := (item.Status == StatusPublished || item.Status == StatusScheduled) && draft.Status != StatusPublished
+
+	status := draft.Status
+	if publish {
+		status, problem = publishStatus(StatusPublished, item.PublishAt)
+		if problem != "" {
+			return syncRejection(c, result, problem)
+		}
+	}
+
+	contentChanged := item.Content != draft.Content || item.ContentFormat != draft.ContentFormat
+
+	draft.Title = item.Title
+	draft.Content = item.Content
+	draft.ContentFormat = item.ContentFormat
+	draft.Latitude = item.Latitude
+	draft.Longitude = item.Longitude
+	draft.Visibility = item.Visibility
+
+	// 🔐 public journal wajib punya lokasi (draft boleh menyusul)
+	if status != StatusDraft && draft.Visibility == VisibilityPublic && !draft.hasLocation() {
+		return syncRejection(c, result, "public journal must have location")
+	}
+
+	revision := draft.Revision
+	if edited {
+		saved, err := saveJournalDraft(ctx, tx, draft, userID, contentChanged)
+		if errors.Is(err, services.ErrInvalidContent) {
+			return syncRejection(c, result, "invalid content")
+		}
+		if err != nil {
+			return syncRejection(c, result, "failed to sync")
+		}
+		revision = saved.Revision
+	}
+
+	var eventID *int
+	if publish {
+		eventID, _, err = publishJournalDraft(ctx, tx, draft.ID, status, item.PublishAt)
+	}
+	if err == nil && item.Tags != nil {
+		err = setJournalTags(ctx, tx, draft.ID, item.Tags)
+		if err == nil {
+			_, err = tx.Exec(ctx, `UPDATE journals SET updated_at = NOW() WHERE id = $1`, draft.ID)
+		}
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		return syncRejection(c, result, "failed to sync")
+	}
+
+	if publish && status == StatusPublished {
+		announceJournal(draft.ID, eventID)
+	}
+
+	result["status"] = syncUnchanged
+	if edited || publish || item.Tags != nil {
+		result["status"] = syncUpdated
+	}
+	result["revision"] = revision
+	result["journal_status"] = status
+
+	return result
+}
+
+func createSyncJournal(ctx context.Context, tx pgx.Tx, c *gin.Context, userID int, item SyncJournal, result gin.H) gin.H {
+	if item.ID != nil {
+		return syncRejection(c, result, "journal not found")
+	}
+
+	status, problem := publishStatus(item.Status, item.PublishAt)
+	if problem != "" {
+		return syncRejection(c, result, problem)
+	}
+
+	journal := newJournal{
+		ClientID:      item.ClientID,
+		EventID:       item.EventID,
+		Title:         item.Title,
+		Content:       item.Content,
+		ContentFormat: item.ContentFormat,
+		Visibility:    item.Visibility,
+		Status:        status,
+		PublishAt:     scheduledAt(status, item.PublishAt),
+		Tags:          item.Tags,
+		OccurredAt:    item.OccurredAt,
+	}
+	if item.Latitude != nil {
+		journal.Latitude = *item.Latitude
+	}
+	if item.Longitude != nil {
+		journal.Longitude = *item.Longitude
+	}
+
+	// 🔐 public journal wajib punya lokasi (draft boleh menyusul)
+	if status != StatusDraft && journal.Visibility == VisibilityPublic && (journal.Latitude == 0 || journal.Longitude == 0) {
+		return syncRejection(c, result, "public journal must have location")
+	}
+
+	if journal.EventID != nil && !organizerEventExists(ctx, *journal.EventID) {
+		return syncRejection(c, result, "invalid event_id")
+	}
+
+	writtenAt := time.Now()
+	if item.OccurredAt != nil {
+		writtenAt = *item.OccurredAt
+	}
+
+	occurrenceStart, err := resolveJournalOccurrence(ctx, journal.EventID, item.OccurrenceStart, writtenAt)
+	if errors.Is(err, errInvalidOccurrence) {
+		return syncRejection(c, result, "invalid occurrence_start")
+	}
+	if err != nil {
+		return syncRejection(c, result, "failed to sync")
+	}
+	journal.OccurrenceStart = occurrenceStart
+
+	created, err := insertJournal(ctx, tx, userID, journal)
+	if errors.Is(err, services.ErrInvalidContent) {
+		return syncRejection(c, result, "invalid content")
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if isUniqueViolation(err) {
+		// batch yang sama sedang diproses di request lain
+		result["status"] = syncConflict
+		result["error"] = tr(c, "journal was changed elsewhere")
+		return result
+	}
+	if err != nil {
+		return syncRejection(c, result, "failed to sync")
+	}
+
+	if status == StatusPublished {
+		announceJournal(created.ID, journal.EventID)
+	}
+
+	result["status"] = syncCreated
+	result["id"] = created.ID
+	result["revision"] = 1
+	result["journal_status"] = status
+	result["verified_attendee"] = created.VerifiedAt != nil
+
+	return result
+}
+
+//
+// ===== SYNC: IMAGES, LIKES & BOOKMARKS =====
+//
+
+func syncImage(c *gin.Context, userID int, item SyncImage) gin.H {
+	result := item.syncJournalRef.result()
+	result["client_id"] = item.ClientID
+
+	if !validClientID(item.ClientID) {
+		return syncRejection(c, result, "invalid client_id")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	journalID, ok := resolveSyncJournal(ctx, userID, item.syncJournalRef)
+	if !ok {
+		return syncRejection(c, result, "journal not found")
+	}
+
+	var ownerID int
+	_ = config.DB.QueryRow(ctx, `SELECT user_id FROM journals WHERE id = $1`, journalID).Scan(&ownerID)
+	if ownerID != userID {
+		return syncRejection(c, result, "not allowed")
+	}
+
+	query := `
+		INSERT INTO journal_images (journal_id, client_id)
+		VALUES ($1, $2)
+		ON CONFLICT (journal_id, client_id) DO UPDATE SET client_id = EXCLUDED.client_id
+		RETURNING id, image_url IS NOT NULL, (xmax = 0)
+	`
+
+	var imageID int
+	var uploaded, created bool
+	err := config.DB.QueryRow(ctx, query, journalID, item.ClientID).Scan(&imageID, &uploaded, &created)
+	if err != nil {
+		return syncRejection(c, result, "failed to sync")
+	}
+
+	result["status"] = syncUnchanged
+	if created {
+		result["status"] = syncCreated
+	}
+	result["journal_id"] = journalID
+	result["id"] = imageID
+	result["uploaded"] = uploaded
+	result["image_url"] = journalImageURL(journalID, imageID)
+
+	return result
+}
+
+func syncLike(c *gin.Context, userID int, item SyncLike) gin.H {
+	result := item.syncJournalRef.result()
+	result["liked"] = item.Liked
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	journalID, ok := resolveSyncJournal(ctx, userID, item.syncJournalRef)
+	if !ok {
+		return syncRejection(c, result, "journal not found")
+	}
+	result["journal_id"] = journalID
+
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return syncRejection(c, result, "failed to sync")
+	}
+	defer tx.Rollback(ctx)
+
+	changed, likeCount, err := toggleJournalLike(ctx, tx, userID, journalID, item.Liked)
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		return syncRejection(c, result, "failed to sync")
+	}
+
+	if changed {
+		bumpLikePopularity(journalID, item.Liked)
+	}
+
+	result["status"] = syncUnchanged
+	if changed {
+		result["status"] = syncUpdated
+	}
+	result["like_count"] = likeCount
+
+	return result
+}
+
+func syncBookmark(c *gin.Context, userID int, item SyncBookmark) gin.H {
+	result := item.syncJournalRef.result()
+	result["deleted"] = item.Deleted
+
+	if item.Note != nil && utf8.RuneCountInString(*item.Note) > maxBookmarkNoteLength {
+		return syncRejection(c, result, "note is too long")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	journalID, ok := resolveSyncJournal(ctx, userID, item.syncJournalRef)
+	if !ok && !item.Deleted {
+		return syncRejection(c, result, "journal not found")
+	}
+
+	// bookmark jurnal yang sudah tidak terlihat tetap boleh dihapus
+	if !ok && item.JournalID != nil {
+		journalID = *item.JournalID
+	}
+	if journalID == 0 {
+		return syncRejection(c, result, "journal not found")
+	}
+	result["journal_id"] = journalID
+
+	if item.CollectionID != nil && !ownsCollection(ctx, userID, *item.CollectionID) {
+		return syncRejection(c, result, "collection not found")
+	}
+
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return syncRejection(c, result, "failed to sync")
+	}
+	defer tx.Rollback(ctx)
+
+	var changed bool
+	if item.Deleted {
+		changed, err = removeBookmark(ctx, tx, userID, journalID)
+	} else {
+		changed, err = saveBookmark(ctx, tx, userID, journalID, item.CollectionID, item.Note)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		return syncRejection(c, result, "failed to sync")
+	}
+
+	if changed {
+		delta := services.PopularityBookmark
+		if item.Deleted {
+			delta = -delta
+		}
+		go services.BumpJournalPopularity(journalID, delta)
+	}
+
+	// catatan/koleksi bisa berubah walau bookmark sudah ada
+	result["status"] = syncUpdated
+	if item.Deleted && !changed {
+		result["status"] = syncUnchanged
+	}
+
+	return result
+}
+
+//
+// ===== SYNC: DOWNLOAD =====
+//
+
+// loadSyncChanges collects one page of the user's journals changed after
+// p, and on the first page the likes and bookmarks that changed since
+// p.Since (everything when it is nil) plus those removed since then. The
+// returned position is where the next page starts, or the finished
+// download when nothing is left.
+func loadSyncChanges(ctx context.Context, userID int, p syncPosition) (gin.H, syncPosition, error) {
+	next := syncPosition{SyncedAt: p.SyncedAt}
+
+	journals, err := loadSyncJournals(ctx, userID, p.AfterUpdated, p.AfterID, syncJournalPage+1)
+	if err != nil {
+		return nil, next, err
+	}
+
+	if len(journals) > syncJournalPage {
+		journals = journals[:syncJournalPage]
+		last := journals[len(journals)-1]
+
+		next.Since = p.Since
+		next.Paging = true
+		next.AfterUpdated = last["updated_at"].(time.Time)
+		next.AfterID = last["id"].(int)
+	}
+
+	// like & bookmark kecil, cukup dikirim di halaman pertama
+	if p.Paging {
+		return gin.H{
+			"journals":  journals,
+			"likes":     []gin.H{},
+			"bookmarks": []gin.H{},
+			"deleted":   gin.H{"likes": []int{}, "bookmarks": []int{}},
+		}, next, nil
+	}
+
+	after := p.AfterUpdated
+	since := p.Since
+
+	likes := []gin.H{}
+	rows, err := config.DB.Query(
+		ctx,
+		`SELECT journal_id, created_at FROM journal_likes WHERE user_id = $1 AND created_at > $2 ORDER BY created_at`,
+		userID,
+		after,
+	)
+	if err != nil {
+		return nil, next, err
+	}
+	for rows.Next() {
+		var journalID int
+		var likedAt time.Time
+		if err := rows.Scan(&journalID, &likedAt); err != nil {
+			rows.Close()
+			return nil, next, err
+		}
+		likes = append(likes, gin.H{"journal_id": journalID, "liked_at": likedAt})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, next, err
+	}
+
+	bookmarks := []gin.H{}
+	rows, err = config.DB.Query(
+		ctx,
+		`
+		SELECT journal_id, collection_id, note, updated_at
+		FROM bookmarks
+		WHERE user_id = $1 AND updated_at > $2
+		ORDER BY updated_at
+		`,
+		userID,
+		after,
+	)
+	if err != nil {
+		return nil, next, err
+	}
+	for rows.Next() {
+		var journalID int
+		var collectionID *int
+		var note *string
+		var updatedAt time.Time
+		if err := rows.Scan(&journalID, &collectionID, &note, &updatedAt); err != nil {
+			rows.Close()
+			return nil, next, err
+		}
+		bookmarks = append(bookmarks, gin.H{
+			"journal_id":    journalID,
+			"collection_id": collectionID,
+			"note":          note,
+			"updated_at":    updatedAt,
+		})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, next, err
+	}
+
+	deleted := gin.H{
+		"likes":     []int{},
+		"bookmarks": []int{},
+	}
+	if since != nil {
+		rows, err = config.DB.Query(
+			ctx,
+			`SELECT kind, journal_id FROM sync_tombstones WHERE user_id = $1 AND deleted_at > $2`,
+			userID,
+			after,
+		)
+		if err != nil {
+			return nil, next, err
+		}
+		removedLikes, removedBookmarks := []int{}, []int{}
+		for rows.Next() {
+			var kind string
+			var journalID int
+			if err := rows.Scan(&kind, &journalID); err != nil {
+				rows.Close()
+				return nil, next, err
+			}
+			if kind == tombstoneLike {
+				removedLikes = append(removedLikes, journalID)
+			} else {
+				removedBookmarks = append(removedBookmarks, journalID)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, next, err
+		}
+		deleted["likes"] = removedLikes
+		deleted["bookmarks"] = removedBookmarks
+	}
+
+	return gin.H{
+		"journals":  journals,
+		"likes":     likes,
+		"bookmarks": bookmarks,
+		"deleted":   deleted,
+	}, next, nil
+}
+
+// loadSyncJournals lists up to limit of the user's journals changed after
+// (afterUpdated, afterID), oldest change first.
+func loadSyncJournals(ctx context.Context, userID int, afterUpdated time.Time, afterID int, limit int) ([]gin.H, error) {
+	query := `
+		SELECT j.id, j.client_id::text, j.event_id, j.title, COALESCE(j.content, ''), j.content_format,
+		       j.latitude, j.longitude, j.visibility, j.status, j.publish_at,
+		       j.revision, j.created_at, j.updated_at, j.occurred_at,
+		       ` + journalTagsSQL("j") + `, j.verified_at IS NOT NULL,
+		       COALESCE((
+		         SELECT json_agg(json_build_object(
+		           'id', ji.id,
+		           'client_id', ji.client_id,
+		           'uploaded', ji.image_url IS NOT NULL
+		         ) ORDER BY ji.id)
+		         FROM journal_images ji
+		         WHERE ji.journal_id = j.id
+		       ), '[]')
+		FROM journals j
+		WHERE j.user_id = $1 AND (j.updated_at, j.id) > ($2, $3)
+		ORDER BY j.updated_at, j.id
+		LIMIT $4
+	`
+
+	rows, err := config.DB.Query(ctx, query, userID, afterUpdated, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	journals := []gin.H{}
+
+	for rows.Next() {
+		var (
+			id                   int
+			clientID             *string
+			eventID              *int
+			title, content       string
+			format               string
+			lat, lng             *float64
+			visibility, status   string
+			publishAt            *time.Time
+			revision             int
+			createdAt, updatedAt time.Time
+			occurredAt           *time.Time
+			tags                 []string
+			verified             bool
+			images               json.RawMessage
+		)
+
+		err := rows.Scan(
+			&id, &clientID, &eventID, &title, &content, &format,
+			&lat, &lng, &visibility, &status, &publishAt,
+			&revision, &createdAt, &updatedAt, &occurredAt,
+			&tags, &verified, &images,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		journals = append(journals, gin.H{
+			"id":                id,
+			"client_id":         clientID,
+			"event_id":          eventID,
+			"title":             title,
+			"content":           content,
+			"content_format":    format,
+			"latitude":          lat,
+			"longitude":         lng,
+			"visibility":        visibility,
+			"status":            status,
+			"publish_at":        publishAt,
+			"revision":          revision,
+			"created_at":        createdAt,
+			"updated_at":        updatedAt,
+			"occurred_at":       occurredAt,
+			"tags":              tags,
+			"verified_attendee": verified,
+			"images":            images,
+		})
+	}
+
+	return journals, rows.Err()
+}
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"event-journal-backend/config"
+	"event-journal-backend/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestLocale picks the language for API messages: the signed-in user's
+// saved locale first, then the Accept-Language header (also for users who
+// never picked one, whose locale is NULL).
+func requestLocale(c *gin.Context) string {
+	if locale := c.GetString("locale"); locale != "" {
+		return locale
+	}
+
+	var locale string
+	if userID := c.GetInt("user_id"); userID != 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		config.DB.QueryRow(ctx, `SELECT COALESCE(locale, '') FROM users WHERE id = $1`, userID).Scan(&locale)
+	}
+
+	if locale == "" {
+		locale = i18n.Negotiate(c.GetHeader("Accept-Language"))
+	}
+
+	c.Set("locale", locale)
+	return locale
+}
+
+// tr translates an API message for the current request.
+func tr(c *gin.Context, msg string, args ...any) string {
+	return i18n.T(requestLocale(c), msg, args...)
+}
+
+func UpdateLocale(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	var input struct {
+		Locale string `json:"locale" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil || !i18n.IsSupported(input.Locale) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":     tr(c, "unsupported locale"),
+			"supported": i18n.Supported,
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := config.DB.Exec(ctx, `UPDATE users SET locale = $1 WHERE id = $2`, input.Locale, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to save locale")})
+		return
+	}
+
+	c.Set("locale", input.Locale)
+
+	c.JSON(http.StatusOK, gin.H{"locale": input.Locale})
+}
@@ -0,0 +1,205 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"event-journal-backend/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LocationInput struct {
+	Name      string   `json:"name"`
+	Latitude  *float64 `json:"latitude" binding:"required"`
+	Longitude *float64 `json:"longitude" binding:"required"`
+}
+
+func validCoordinates(lat, lng float64) bool {
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
+
+//
+// ===== HOME AREA =====
+//
+
+func SaveHomeArea(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	var input LocationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !validCoordinates(*input.Latitude, *input.Longitude) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid coordinates")})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE users
+		SET home_latitude = $1,
+		    home_longitude = $2,
+		    home_location_name = $3
+		WHERE id = $4
+	`
+
+	_, err := config.DB.Exec(ctx, query, *input.Latitude, *input.Longitude, input.Name, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to save home area")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"home_area": gin.H{
+			"name":      input.Name,
+			"latitude":  *input.Latitude,
+			"longitude": *input.Longitude,
+		},
+	})
+}
+
+func ClearHomeArea(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE users
+		SET home_latitude = NULL,
+		    home_longitude = NULL,
+		    home_location_name = NULL
+		WHERE id = $1
+	`
+
+	if _, err := config.DB.Exec(ctx, query, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to clear home area")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "home area cleared"})
+}
+
+//
+// ===== FOLLOWED LOCATIONS =====
+//
+
+func FollowLocation(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	var input LocationInput
+	if err := c.ShouldBindJSON(&input); err != nil || input.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "name, latitude and longitude required")})
+		return
+	}
+
+	if !validCoordinates(*input.Latitude, *input.Longitude) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid coordinates")})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO user_followed_locations (user_id, name, latitude, longitude)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	var id int
+	err := config.DB.QueryRow(ctx, query, userID, input.Name, *input.Latitude, *input.Longitude).
+		Scan(&id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to follow location")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"id":        id,
+		"name":      input.Name,
+		"latitude":  *input.Latitude,
+		"longitude": *input.Longitude,
+	})
+}
+
+func GetFollowedLocations(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT id, name, latitude, longitude, created_at
+		FROM user_followed_locations
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := config.DB.Query(ctx, query, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch locations")})
+		return
+	}
+	defer rows.Close()
+
+	var locations []gin.H
+
+	for rows.Next() {
+		var id int
+		var name string
+		var lat, lng float64
+		var createdAt time.Time
+
+		if err := rows.Scan(&id, &name, &lat, &lng, &createdAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to read location")})
+			return
+		}
+
+		locations = append(locations, gin.H{
+			"id":         id,
+			"name":       name,
+			"latitude":   lat,
+			"longitude":  lng,
+			"created_at": createdAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": locations,
+	})
+}
+
+func UnfollowLocation(c *gin.Context) {
+	locationID := c.Param("id")
+	userID := c.GetInt("user_id")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `
+		DELETE FROM user_followed_locations
+		WHERE id = $1 AND user_id = $2
+	`
+
+	result, err := config.DB.Exec(ctx, query, locationID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to unfollow location")})
+		return
+	}
+
+	if result.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "location not found")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "location unfollowed",
+	})
+}
@@ -6,26 +6,32 @@ import (
 	"time"
 
 	"event-journal-backend/config"
+	"event-journal-backend/services"
 
 	"github.com/gin-gonic/gin"
 )
 
+// GetMapJournals lists public journals with a location. ?preview_length
+// overrides the default preview size.
 func GetMapJournals(c *gin.Context) {
+	previewLength := services.PreviewLength(c.Query("preview_length"))
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	query := `
-		SELECT id, title, content, latitude, longitude
-		FROM journals
-		WHERE is_public = true
-		  AND latitude IS NOT NULL
-		  AND longitude IS NOT NULL
-		ORDER BY created_at DESC
+		SELECT j.id, j.title, j.excerpt, j.latitude, j.longitude,
+		       ` + engagementColumns("j", "$1") + `
+		FROM journals j
+		WHERE j.is_public = true
+		  AND j.latitude IS NOT NULL
+		  AND j.longitude IS NOT NULL
+		ORDER BY j.created_at DESC
 	`
 
-	rows, err := config.DB.Query(ctx, query)
+	rows, err := config.DB.Query(ctx, query, c.GetInt("user_id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch map journals"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch map journals")})
 		return
 	}
 	defer rows.Close()
@@ -36,28 +42,25 @@ func GetMapJournals(c *gin.Context) {
 		var (
 			id      int
 			title   string
-			content string
+			excerpt string
 			lat     float64
 			lng     float64
+
+			engagement journalEngagement
 		)
 
-		if err := rows.Scan(&id, &title, &content, &lat, &lng); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read journal"})
+		if err := rows.Scan(append([]any{&id, &title, &excerpt, &lat, &lng}, engagement.scanTargets()...)...); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to read journal")})
 			return
 		}
 
-		preview := content
-		if len(preview) > 80 {
-			preview = preview[:80] + "..."
-		}
-
-		journals = append(journals, gin.H{
+		journals = append(journals, engagement.into(gin.H{
 			"id":        id,
 			"title":     title,
-			"preview":   preview,
+			"preview":   services.TruncateRunes(excerpt, previewLength),
 			"latitude":  lat,
 			"longitude": lng,
-		})
+		}))
 	}
 
 	c.JSON(http.StatusOK, gin.H{
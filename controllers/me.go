@@ -13,28 +13,68 @@ import (
 func Me(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": tr(c, "unauthorized")})
 		return
 	}
 
 	var user struct {
-		ID    int    `json:"id"`
-		Name  string `json:"name"`
-		Email string `json:"email"`
+		ID          int     `json:"id"`
+		Name        string  `json:"name"`
+		Email       string  `json:"email"`
+		Username    string  `json:"username"`
+		DisplayName *string `json:"display_name"`
+		Bio         *string `json:"bio"`
+		AvatarURL   *string `json:"avatar_url"`
+		Locale      *string `json:"locale"` // null = belum memilih
+		DigestOptIn bool    `json:"digest_enabled"`
+		HomeArea    *gin.H  `json:"home_area"`
 	}
 
+	var (
+		homeName *string
+		homeLat  *float64
+		homeLng  *float64
+	)
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := `SELECT id, name, email FROM users WHERE id=$1`
+	query := `
+		SELECT id, name, email, username, display_name, bio, avatar_url,
+		       locale, digest_opt_in,
+		       home_location_name, home_latitude, home_longitude
+		FROM users
+		WHERE id = $1
+	`
 	err := config.DB.QueryRow(ctx, query, userID).
-		Scan(&user.ID, &user.Name, &user.Email)
+		Scan(
+			&user.ID,
+			&user.Name,
+			&user.Email,
+			&user.Username,
+			&user.DisplayName,
+			&user.Bio,
+			&user.AvatarURL,
+			&user.Locale,
+			&user.DigestOptIn,
+			&homeName,
+			&homeLat,
+			&homeLng,
+		)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "user not found"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "user not found")})
 		return
 	}
 
+	if homeLat != nil && homeLng != nil {
+		user.HomeArea = &gin.H{
+			"name":      homeName,
+			"latitude":  *homeLat,
+			"longitude": *homeLng,
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"user": user,
 	})
@@ -2,12 +2,18 @@ package controllers
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net/http"
+	"sort"
+	"strconv"
 	"time"
 
 	"event-journal-backend/config"
+	"event-journal-backend/services"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 )
 
 // ===============================
@@ -25,6 +31,11 @@ func CreateOrganizerEvent(c *gin.Context) {
 		Longitude    float64   `json:"longitude" binding:"required"`
 		LocationName string    `json:"location_name" binding:"required"`
 		IsPaid       bool      `json:"is_paid"`
+		Categories   []string  `json:"categories"` // slug kategori dari admin
+
+		// RRULE, mis. "FREQ=WEEKLY;BYDAY=SA"; start_date/end_date = kejadian pertama
+		RecurrenceRule string `json:"recurrence_rule"`
+		Timezone       string `json:"timezone"` // IANA, default UTC
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -32,25 +43,47 @@ func CreateOrganizerEvent(c *gin.Context) {
 		return
 	}
 
+	categories, ok := normalizeCategoriesInput(c, req.Categories)
+	if !ok {
+		return
+	}
+
+	rule, timezone, ok := parseEventRecurrence(c, req.RecurrenceRule, req.Timezone, req.StartDate, req.EndDate)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
     INSERT INTO events (
         title, description, start_date, end_date,
         latitude, longitude, location_name,
         is_paid, event_type,
-        status, created_by
+        status, created_by,
+        recurrence_rule, timezone
     )
     VALUES (
         $1,$2,$3,$4,
         $5,$6,$7,
         $8,'organizer',
         'pending',
-        $9)
+        $9,
+        $10,$11)
     RETURNING id
 `
 	var eventID int
 
-	err := config.DB.QueryRow(
-		context.Background(),
+	err = tx.QueryRow(
+		ctx,
 		query,
 		req.Title,
 		req.Description,
@@ -61,8 +94,20 @@ func CreateOrganizerEvent(c *gin.Context) {
 		req.LocationName,
 		req.IsPaid,
 		userID,
+		rule,
+		timezone,
 	).Scan(&eventID)
 
+	if err == nil {
+		err = setEventCategories(ctx, tx, eventID, categories)
+	}
+	if errors.Is(err, errUnknownCategory) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "unknown category")})
+		return
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -72,83 +117,248 @@ func CreateOrganizerEvent(c *gin.Context) {
 		"id":         eventID,
 		"event_type": "organizer",
 		"is_public":  true,
+		"categories": categories,
+
+		"recurrence_rule": rule,
+		"timezone":        timezone,
 	})
 }
 
 // ===============================
 // ORGANIZER EVENT DETAIL
 // ===============================
+
+// recentJournalsPreviewLimit is how many public journals the event
+// detail shows; the full list is GET /events/:id/journals.
+const recentJournalsPreviewLimit = 5
+
+// GetOrganizerEventDetail returns an organizer event with its organizer,
+// journal and attendee counts and the latest public journals. Approved
+// events are public; the organizer and admins also see pending and
+// rejected ones. Attendees are users with a verified check-in journal.
 func GetOrganizerEventDetail(c *gin.Context) {
-	id := c.Param("id")
+	eventID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid event id")})
+		return
+	}
+
+	viewerID := c.GetInt("user_id")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
 	query := `
 		SELECT
-			id,
-			title,
-			description,
-			event_date,
-			latitude,
-			longitude,
-			location_name,
-			created_at
-		FROM events
-		WHERE id = $1
+			e.id,
+			e.title,
+			COALESCE(e.description, ''),
+			e.start_date,
+			e.end_date,
+			e.latitude,
+			e.longitude,
+			e.location_name,
+			e.is_paid,
+			e.status,
+			e.created_at,
+			` + eventCategoriesSQL("e") + `,
+			(SELECT COUNT(*) FROM journals j
+			 WHERE j.event_id = e.id AND j.is_public = true),
+			(SELECT COUNT(DISTINCT j.user_id) FROM journals j
+			 WHERE j.event_id = e.id AND j.verified_at IS NOT NULL),
+			` + profileSummaryColumns("u") + `
+		FROM events e
+		JOIN users u ON u.id = e.created_by
+		WHERE e.id = $1
+		  AND e.event_type = 'organizer'
+		  AND (e.status = 'approved' OR e.created_by = $2 OR $3)
 	`
 
 	var (
-		eventID      int
-		title        string
-		description  string
-		eventDate    time.Time
-		lat          float64
-		lng          float64
-		locationName string
-		createdAt    time.Time
+		title         string
+		description   string
+		startDate     time.Time
+		endDate       time.Time
+		lat           float64
+		lng           float64
+		locationName  string
+		isPaid        bool
+		status        string
+		createdAt     time.Time
+		categories    []string
+		journalCount  int
+		attendeeCount int
+		organizer     profileSummary
 	)
 
-	err := config.DB.QueryRow(
-		context.Background(),
+	err = config.DB.QueryRow(
+		ctx,
 		query,
-		id,
+		eventID,
+		viewerID,
+		c.GetString("role") == "admin",
 	).Scan(
 		&eventID,
 		&title,
 		&description,
-		&eventDate,
+		&startDate,
+		&endDate,
 		&lat,
 		&lng,
 		&locationName,
+		&isPaid,
+		&status,
 		&createdAt,
+		&categories,
+		&journalCount,
+		&attendeeCount,
+		&organizer.ID,
+		&organizer.Username,
+		&organizer.DisplayName,
+		&organizer.AvatarURL,
 	)
 
+	// event pending/rejected milik orang lain → 404, bukan 403
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "event not found")})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "event not found"})
+		log.Println("FAILED TO FETCH EVENT DETAIL:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch event")})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"id":            eventID,
-		"title":         title,
-		"description":   description,
-		"start_date":    eventDate,
-		"end_date":      nil, // belum disimpan di DB
-		"latitude":      lat,
-		"longitude":     lng,
-		"location_name": locationName,
-		"is_paid":       false, // rule di code
-		"event_type":    "organizer",
-		"is_public":     true,
-		"created_at":    createdAt,
-	})
+	journals, err := recentEventJournals(ctx, eventID, viewerID, recentJournalsPreviewLimit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch journals")})
+		return
+	}
+
+	if status == "approved" {
+		go services.BumpEventPopularity(eventID, services.PopularityView)
+	}
+
+	response := gin.H{
+		"id":              eventID,
+		"title":           title,
+		"description":     description,
+		"start_date":      startDate,
+		"end_date":        endDate,
+		"latitude":        lat,
+		"longitude":       lng,
+		"location_name":   locationName,
+		"is_paid":         isPaid,
+		"status":          status,
+		"event_type":      "organizer",
+		"is_public":       status == "approved",
+		"created_at":      createdAt,
+		"categories":      categories,
+		"organizer":       organizer,
+		"journal_count":   journalCount,
+		"attendee_count":  attendeeCount,
+		"recent_journals": journals,
+	}
+
+	// 🔁 event berulang: tampilkan kejadian berikutnya
+	schedule, err := loadEventSchedule(ctx, eventID)
+	if err == nil && schedule.recurring() {
+		occurrences, err := upcomingOccurrences(ctx, schedule)
+		if err != nil {
+			log.Println("FAILED TO FETCH EVENT OCCURRENCES:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch event")})
+			return
+		}
+
+		response["recurrence_rule"] = schedule.Rule
+		response["timezone"] = schedule.Timezone
+		response["occurrences"] = occurrences
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// recentEventJournals lists the latest public journals of an event with
+// their authors, for previews. viewerID (0 for anonymous) fills in
+// liked_by_me and bookmarked_by_me.
+func recentEventJournals(ctx context.Context, eventID, viewerID, limit int) ([]gin.H, error) {
+	query := `
+		SELECT j.id, j.title, j.excerpt, j.created_at,
+		       j.verified_at IS NOT NULL, j.occurrence_start,
+		       ` + profileSummaryColumns("u") + `,
+		       ` + engagementColumns("j", "$3") + `
+		FROM journals j
+		JOIN users u ON u.id = j.user_id
+		WHERE j.event_id = $1 AND j.is_public = true
+		ORDER BY j.created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := config.DB.Query(ctx, query, eventID, limit, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	journals := []gin.H{}
+
+	for rows.Next() {
+		var (
+			id         int
+			title      string
+			excerpt    string
+			createdAt  time.Time
+			verified   bool
+			occurrence *time.Time
+			author     profileSummary
+			engagement journalEngagement
+		)
+
+		targets := []any{
+			&id,
+			&title,
+			&excerpt,
+			&createdAt,
+			&verified,
+			&occurrence,
+			&author.ID,
+			&author.Username,
+			&author.DisplayName,
+			&author.AvatarURL,
+		}
+		if err := rows.Scan(append(targets, engagement.scanTargets()...)...); err != nil {
+			return nil, err
+		}
+
+		journals = append(journals, engagement.into(gin.H{
+			"id":               id,
+			"title":            title,
+			"excerpt":          excerpt,
+			"created_at":       createdAt,
+			"author":           author,
+			"occurrence_start": occurrence,
+
+			"verified_attendee": verified,
+		}))
+	}
+
+	return journals, rows.Err()
 }
 
 // ===============================
 // SEARCH ORGANIZER EVENTS
 // ===============================
 func SearchOrganizerEvents(c *gin.Context) {
-	start := c.Query("start_date")
-	end := c.Query("end_date")
+	from, to, ok := searchWindow(c)
+	if !ok {
+		return
+	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// event berulang diambil bila kejadian pertamanya sebelum akhir rentang,
+	// kejadiannya diuraikan di bawah
 	query := `
         SELECT
             id,
@@ -157,76 +367,169 @@ func SearchOrganizerEvents(c *gin.Context) {
             end_date,
             latitude,
             longitude,
-            location_name
+            location_name,
+            is_paid,
+            recurrence_rule,
+            timezone,
+            ` + eventCategoriesSQL("events") + `
         FROM events
         WHERE event_type = 'organizer'
-        AND status = 'published'
-        AND start_date >= $1
-        AND end_date <= $2
+        AND status = 'approved'
+        AND (
+            (recurrence_rule IS NULL AND start_date >= $1 AND end_date <= $2)
+            OR (recurrence_rule IS NOT NULL AND start_date < $2)
+        )
+        AND ` + eventInCategorySQL("events", "$3") + `
         ORDER BY start_date ASC
     `
 
 	rows, err := config.DB.Query(
-		context.Background(),
+		ctx,
 		query,
-		start,
-		end,
+		from,
+		to,
+		categoryQuery(c),
 	)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		log.Println("FAILED TO SEARCH EVENTS:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch events")})
 		return
 	}
 	defer rows.Close()
 
-	var results []gin.H
+	type searchedEvent struct {
+		schedule     eventSchedule
+		title        string
+		latitude     float64
+		longitude    float64
+		locationName string
+		isPaid       bool
+		categories   []string
+	}
+
+	var (
+		events    []searchedEvent
+		recurring []int
+	)
 
 	for rows.Next() {
-		var (
-			id           int
-			title        string
-			startDate    time.Time
-			endDate      time.Time
-			latitude     float64
-			longitude    float64
-			locationName string
-		)
+		var e searchedEvent
 
 		err := rows.Scan(
-			&id,
-			&title,
-			&startDate,
-			&endDate,
-			&latitude,
-			&longitude,
-			&locationName,
+			&e.schedule.ID,
+			&e.title,
+			&e.schedule.StartDate,
+			&e.schedule.EndDate,
+			&e.latitude,
+			&e.longitude,
+			&e.locationName,
+			&e.isPaid,
+			&e.schedule.Rule,
+			&e.schedule.Timezone,
+			&e.categories,
 		)
 
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			log.Println("FAILED TO SEARCH EVENTS:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch events")})
 			return
 		}
 
-		results = append(results, gin.H{
-			"id":            id,
-			"title":         title,
-			"start_date":    startDate,
-			"end_date":      endDate,
-			"latitude":      latitude,
-			"longitude":     longitude,
-			"location_name": locationName,
-			"is_paid":       false,
-			"event_type":    "organizer",
-			"is_public":     true,
-		})
+		events = append(events, e)
+		if e.schedule.recurring() {
+			recurring = append(recurring, e.schedule.ID)
+		}
 	}
 
 	if err := rows.Err(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		log.Println("FAILED TO SEARCH EVENTS:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch events")})
 		return
 	}
 
+	cancelled, err := loadOccurrenceCancellations(ctx, recurring, from, to)
+	if err != nil {
+		log.Println("FAILED TO SEARCH EVENTS:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch events")})
+		return
+	}
+
+	var results []gin.H
+
+	for _, e := range events {
+		for _, o := range e.schedule.occurrences(from, to, maxEventOccurrences) {
+			// sama seperti event biasa: kejadian harus utuh di dalam rentang
+			if o.Start.Before(from) || o.End.After(to) {
+				continue
+			}
+
+			result := gin.H{
+				"id":            e.schedule.ID,
+				"title":         e.title,
+				"start_date":    o.Start,
+				"end_date":      o.End,
+				"latitude":      e.latitude,
+				"longitude":     e.longitude,
+				"location_name": e.locationName,
+				"is_paid":       e.isPaid,
+				"event_type":    "organizer",
+				"is_public":     true,
+				"categories":    e.categories,
+				"recurring":     e.schedule.recurring(),
+			}
+
+			if e.schedule.recurring() {
+				occurrence := occurrenceJSON(e.schedule.ID, o, cancelled)
+				result["recurrence_rule"] = e.schedule.Rule
+				result["occurrence_start"] = o.Start
+				result["cancelled"] = occurrence["cancelled"]
+				if reason, ok := occurrence["cancellation_reason"]; ok {
+					result["cancellation_reason"] = reason
+				}
+			}
+
+			results = append(results, result)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i]["start_date"].(time.Time).Before(results[j]["start_date"].(time.Time))
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"data": results,
 	})
 }
+
+// searchWindow reads the start_date/end_date range of an event search.
+// It defaults to the next 90 days, so recurring events always expand over
+// a bounded range. It writes the error response itself.
+func searchWindow(c *gin.Context) (time.Time, time.Time, bool) {
+	from := time.Now()
+	if value := c.Query("start_date"); value != "" {
+		t, err := parseDateParam(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid date range")})
+			return from, from, false
+		}
+		from = t
+	}
+
+	to := from.Add(defaultOccurrenceWindow)
+	if value := c.Query("end_date"); value != "" {
+		t, err := parseDateParam(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid date range")})
+			return from, from, false
+		}
+		to = t
+	}
+
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid date range")})
+		return from, from, false
+	}
+
+	return from, to, true
+}
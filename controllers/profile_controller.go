@@ -0,0 +1,289 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"event-journal-backend/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxBioLength  = 500
+	maxAvatarSize = 5 << 20
+)
+
+var avatarExtensions = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".webp": true,
+}
+
+type UpdateProfileInput struct {
+	Username    *string `json:"username"`
+	DisplayName *string `json:"display_name"`
+	Bio         *string `json:"bio"`
+}
+
+//
+// ===== UPDATE PROFILE =====
+//
+
+func UpdateProfile(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	var input UpdateProfileInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Username != nil {
+		username := strings.ToLower(strings.TrimSpace(*input.Username))
+		if !validUsername(username) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "username must be 3-30 characters of a-z, 0-9, _ or .")})
+			return
+		}
+		input.Username = &username
+	}
+
+	if input.DisplayName != nil {
+		name := strings.TrimSpace(*input.DisplayName)
+		if utf8.RuneCountInString(name) > 50 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "display name is too long")})
+			return
+		}
+		input.DisplayName = &name
+	}
+
+	if input.Bio != nil && utf8.RuneCountInString(*input.Bio) > maxBioLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "bio is too long")})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE users SET
+			username = COALESCE($1, username),
+			display_name = COALESCE($2, display_name),
+			bio = COALESCE($3, bio)
+		WHERE id = $4
+	`
+
+	_, err := config.DB.Exec(ctx, query, input.Username, input.DisplayName, input.Bio, userID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": tr(c, "username already taken")})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to update profile")})
+		return
+	}
+
+	Me(c)
+}
+
+//
+// ===== UPLOAD AVATAR =====
+//
+
+func UploadAvatar(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	file, err := c.FormFile("avatar")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "avatar is required")})
+		return
+	}
+
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	if !avatarExtensions[ext] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "avatar must be a jpg, png or webp image")})
+		return
+	}
+
+	if file.Size > maxAvatarSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "avatar must be at most 5 MB")})
+		return
+	}
+
+	filename := fmt.Sprintf("user_%d_%d%s", userID, time.Now().Unix(), ext)
+
+	if err := os.MkdirAll("uploads/avatars", 0o755); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to save image")})
+		return
+	}
+
+	if err := c.SaveUploadedFile(file, "uploads/avatars/"+filename); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to save image")})
+		return
+	}
+
+	avatarURL := "/uploads/avatars/" + filename
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = config.DB.Exec(ctx, `UPDATE users SET avatar_url = $1 WHERE id = $2`, avatarURL, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to update profile")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"avatar_url": avatarURL,
+	})
+}
+
+//
+// ===== PUBLIC PROFILE =====
+//
+
+func GetUserProfile(c *gin.Context) {
+	username := strings.ToLower(c.Param("username"))
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	offset := (page - 1) * limit
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var (
+		profile   profileSummary
+		bio       *string
+		joinedAt  time.Time
+		journals  int
+		likes     int
+		eventsHit int
+		followers int
+		following int
+	)
+
+	profileQuery := `
+		SELECT ` + profileSummaryColumns("u") + `, u.bio, u.created_at,
+		       (SELECT COUNT(*) FROM journals j
+		        WHERE j.user_id = u.id AND j.is_public = true),
+		       (SELECT COALESCE(SUM(j.like_count), 0) FROM journals j
+		        WHERE j.user_id = u.id AND j.is_public = true),
+		       (SELECT COUNT(*) FROM events e
+		        WHERE e.created_by = u.id AND e.status = 'approved'),
+		       (SELECT COUNT(*) FROM user_follows f WHERE f.followee_id = u.id),
+		       (SELECT COUNT(*) FROM user_follows f WHERE f.follower_id = u.id)
+		FROM users u
+		WHERE LOWER(u.username) = $1
+	`
+
+	err := config.DB.QueryRow(ctx, profileQuery, username).Scan(
+		&profile.ID,
+		&profile.Username,
+		&profile.DisplayName,
+		&profile.AvatarURL,
+		&bio,
+		&joinedAt,
+		&journals,
+		&likes,
+		&eventsHit,
+		&followers,
+		&following,
+	)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "user not found")})
+		return
+	}
+
+	// follower juga melihat jurnal "followers"; unlisted tidak pernah ditampilkan
+	listed := `j.status = 'published' AND (j.visibility = 'public' OR (j.visibility = 'followers' AND ` + journalVisibleSQL("j", "$2") + `))`
+
+	var listedTotal int
+	countQuery := `SELECT COUNT(*) FROM journals j WHERE j.user_id = $1 AND ` + listed
+	_ = config.DB.QueryRow(ctx, countQuery, profile.ID, c.GetInt("user_id")).Scan(&listedTotal)
+
+	journalQuery := `
+		SELECT j.id, j.title, j.content, j.latitude, j.longitude, j.visibility, j.created_at,
+		       ` + engagementColumns("j", "$2") + `
+		FROM journals j
+		WHERE j.user_id = $1
+		  AND ` + listed + `
+		ORDER BY j.created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := config.DB.Query(ctx, journalQuery, profile.ID, c.GetInt("user_id"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch journals")})
+		return
+	}
+	defer rows.Close()
+
+	var publicJournals []gin.H
+
+	for rows.Next() {
+		var id int
+		var title, content string
+		var lat, lng *float64
+		var visibility string
+		var createdAt time.Time
+		var engagement journalEngagement
+
+		if err := rows.Scan(append([]any{&id, &title, &content, &lat, &lng, &visibility, &createdAt}, engagement.scanTargets()...)...); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to read journal")})
+			return
+		}
+
+		publicJournals = append(publicJournals, engagement.into(gin.H{
+			"id":         id,
+			"title":      title,
+			"content":    content,
+			"latitude":   lat,
+			"longitude":  lng,
+			"visibility": visibility,
+			"created_at": createdAt,
+		}))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user": gin.H{
+			"id":           profile.ID,
+			"username":     profile.Username,
+			"display_name": profile.DisplayName,
+			"avatar_url":   profile.AvatarURL,
+			"bio":          bio,
+			"joined_at":    joinedAt,
+		},
+		"stats": gin.H{
+			"public_journals": journals,
+			"likes_received":  likes,
+			"approved_events": eventsHit,
+			"followers":       followers,
+			"following":       following,
+		},
+		"journals": publicJournals,
+		"pagination": gin.H{
+			"page":        page,
+			"limit":       limit,
+			"total":       listedTotal,
+			"total_pages": int(math.Ceil(float64(listedTotal) / float64(limit))),
+		},
+	})
+}
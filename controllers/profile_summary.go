@@ -0,0 +1,29 @@
+package controllers
+
+import (
+	"regexp"
+)
+
+// profileSummary is the public face of a user in author/creator blocks.
+// It never contains the email address.
+type profileSummary struct {
+	ID          int     `json:"id"`
+	Username    string  `json:"username"`
+	DisplayName string  `json:"display_name"`
+	AvatarURL   *string `json:"avatar_url"`
+}
+
+// profileSummaryColumns selects the profileSummary fields of the users
+// table aliased as alias, in scan order.
+func profileSummaryColumns(alias string) string {
+	return alias + ".id, " +
+		alias + ".username, " +
+		"COALESCE(NULLIF(" + alias + ".display_name, ''), " + alias + ".name), " +
+		alias + ".avatar_url"
+}
+
+var usernamePattern = regexp.MustCompile(`^[a-z0-9_.]{3,30}$`)
+
+func validUsername(username string) bool {
+	return usernamePattern.MatchString(username)
+}
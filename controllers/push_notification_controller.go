@@ -17,13 +17,13 @@ func SaveFCMToken(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid body"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid body")})
 		return
 	}
 
 	err := services.SaveUserFCMToken(userID, body.Token)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save token"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to save token")})
 		return
 	}
 
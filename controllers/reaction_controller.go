@@ -0,0 +1,235 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"event-journal-backend/config"
+	"event-journal-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// reactionSummary is the per-reaction count of one journal or comment plus
+// the reactions the current viewer left on it.
+type reactionSummary struct {
+	Counts map[string]int `json:"reactions"`
+	Mine   []string       `json:"my_reactions"`
+}
+
+func newReactionSummary() *reactionSummary {
+	return &reactionSummary{Counts: map[string]int{}, Mine: []string{}}
+}
+
+// queryReactions runs a query selecting (item id, reaction, count, mine)
+// and groups the rows by item id.
+func queryReactions(ctx context.Context, query string, args ...any) (map[int]*reactionSummary, error) {
+	rows, err := config.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summaries := map[int]*reactionSummary{}
+
+	for rows.Next() {
+		var itemID, count int
+		var reaction string
+		var mine bool
+
+		if err := rows.Scan(&itemID, &reaction, &count, &mine); err != nil {
+			return nil, err
+		}
+
+		summary, ok := summaries[itemID]
+		if !ok {
+			summary = newReactionSummary()
+			summaries[itemID] = summary
+		}
+
+		summary.Counts[reaction] = count
+		if mine {
+			summary.Mine = append(summary.Mine, reaction)
+		}
+	}
+
+	return summaries, rows.Err()
+}
+
+func loadJournalReactions(ctx context.Context, journalID, viewerID int) (*reactionSummary, error) {
+	query := `
+		SELECT journal_id, reaction, COUNT(*), BOOL_OR(user_id = $2)
+		FROM journal_reactions
+		WHERE journal_id = $1
+		GROUP BY journal_id, reaction
+		ORDER BY MIN(created_at)
+	`
+
+	summaries, err := queryReactions(ctx, query, journalID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if summary, ok := summaries[journalID]; ok {
+		return summary, nil
+	}
+	return newReactionSummary(), nil
+}
+
+func loadCommentReactions(ctx context.Context, commentID, viewerID int) (*reactionSummary, error) {
+	query := `
+		SELECT comment_id, reaction, COUNT(*), BOOL_OR(user_id = $2)
+		FROM comment_reactions
+		WHERE comment_id = $1
+		GROUP BY comment_id, reaction
+		ORDER BY MIN(created_at)
+	`
+
+	summaries, err := queryReactions(ctx, query, commentID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if summary, ok := summaries[commentID]; ok {
+		return summary, nil
+	}
+	return newReactionSummary(), nil
+}
+
+// loadThreadReactions returns the reactions of every comment on a journal,
+// keyed by comment id.
+func loadThreadReactions(ctx context.Context, journalID, viewerID int) (map[int]*reactionSummary, error) {
+	query := `
+		SELECT r.comment_id, r.reaction, COUNT(*), BOOL_OR(r.user_id = $2)
+		FROM comment_reactions r
+		JOIN comments c ON c.id = r.comment_id
+		WHERE c.journal_id = $1
+		GROUP BY r.comment_id, r.reaction
+		ORDER BY MIN(r.created_at)
+	`
+
+	return queryReactions(ctx, query, journalID, viewerID)
+}
+
+//
+// ===== JOURNAL REACTIONS =====
+//
+
+func AddJournalReaction(c *gin.Context) {
+	setJournalReaction(c, true)
+}
+
+func RemoveJournalReaction(c *gin.Context) {
+	setJournalReaction(c, false)
+}
+
+func setJournalReaction(c *gin.Context, add bool) {
+	userID := c.GetInt("user_id")
+	reaction := c.Param("reaction")
+
+	if !services.IsAllowedReaction(reaction) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "unsupported reaction")})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	journal, ok := findVisibleJournal(ctx, c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	query := `
+		INSERT INTO journal_reactions (journal_id, user_id, reaction)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`
+	if !add {
+		query = `
+			DELETE FROM journal_reactions
+			WHERE journal_id = $1 AND user_id = $2 AND reaction = $3
+		`
+	}
+
+	if _, err := config.DB.Exec(ctx, query, journal.ID, userID, reaction); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to update reaction")})
+		return
+	}
+
+	summary, err := loadJournalReactions(ctx, journal.ID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch reactions")})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+//
+// ===== COMMENT REACTIONS =====
+//
+
+func AddCommentReaction(c *gin.Context) {
+	setCommentReaction(c, true)
+}
+
+func RemoveCommentReaction(c *gin.Context) {
+	setCommentReaction(c, false)
+}
+
+func setCommentReaction(c *gin.Context, add bool) {
+	userID := c.GetInt("user_id")
+	reaction := c.Param("reaction")
+
+	if !services.IsAllowedReaction(reaction) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "unsupported reaction")})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	comment, err := findComment(ctx, c.Param("id"))
+	if err != nil || comment.Deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "comment not found")})
+		return
+	}
+
+	if _, ok := findVisibleJournal(ctx, c, strconv.Itoa(comment.JournalID)); !ok {
+		return
+	}
+
+	query := `
+		INSERT INTO comment_reactions (comment_id, user_id, reaction)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`
+	if !add {
+		query = `
+			DELETE FROM comment_reactions
+			WHERE comment_id = $1 AND user_id = $2 AND reaction = $3
+		`
+	}
+
+	if _, err := config.DB.Exec(ctx, query, comment.ID, userID, reaction); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to update reaction")})
+		return
+	}
+
+	summary, err := loadCommentReactions(ctx, comment.ID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch reactions")})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+func GetReactionOptions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"data": services.AllowedReactions(),
+	})
+}
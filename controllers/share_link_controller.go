@@ -0,0 +1,368 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"time"
+
+	"event-journal-backend/config"
+	"event-journal-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxShareLinkHours = 365 * 24
+
+	// sharedImageGrace is how long images of a link that is out of views
+	// still load after its last open.
+	sharedImageGrace = 10 * time.Minute
+)
+
+type CreateShareLinkInput struct {
+	ExpiresInHours *int `json:"expires_in_hours"` // kosong → tidak kedaluwarsa
+	MaxViews       *int `json:"max_views"`        // kosong → tanpa batas
+}
+
+func newShareToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func sharedJournalURL(token string) string {
+	return "/api/shared/" + token
+}
+
+// ownJournal checks that the current user wrote journalID, writing the
+// 404/403 response itself when not.
+func ownJournal(ctx context.Context, c *gin.Context, journalID string) (int, bool) {
+	var id, ownerID int
+	err := config.DB.QueryRow(ctx, `SELECT id, user_id FROM journals WHERE id = $1`, journalID).Scan(&id, &ownerID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "journal not found")})
+		return 0, false
+	}
+
+	if ownerID != c.GetInt("user_id") {
+		c.JSON(http.StatusForbidden, gin.H{"error": tr(c, "not allowed")})
+		return 0, false
+	}
+
+	return id, true
+}
+
+//
+// ===== MANAGE SHARE LINKS (AUTHOR) =====
+//
+
+func CreateShareLink(c *gin.Context) {
+	var input CreateShareLinkInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var expiresAt *time.Time
+	if input.ExpiresInHours != nil {
+		// cek angkanya dulu: jam yang sangat besar bisa overflow di time.Duration
+		hours := *input.ExpiresInHours
+		if hours < 1 || hours > maxShareLinkHours {
+			c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "expires_in_hours must be between 1 and 8760")})
+			return
+		}
+		t := time.Now().Add(time.Duration(hours) * time.Hour)
+		expiresAt = &t
+	}
+
+	if input.MaxViews != nil && *input.MaxViews < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "max_views must be at least 1")})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	journalID, ok := ownJournal(ctx, c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to create share link")})
+		return
+	}
+
+	query := `
+		INSERT INTO journal_share_links (journal_id, token, expires_at, max_views)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+
+	var id int
+	var createdAt time.Time
+	err = config.DB.QueryRow(ctx, query, journalID, token, expiresAt, input.MaxViews).Scan(&id, &createdAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to create share link")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"id":         id,
+		"token":      token,
+		"url":        sharedJournalURL(token),
+		"expires_at": expiresAt,
+		"max_views":  input.MaxViews,
+		"view_count": 0,
+		"created_at": createdAt,
+	})
+}
+
+func GetShareLinks(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	journalID, ok := ownJournal(ctx, c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	query := `
+		SELECT id, token, expires_at, max_views, view_count, last_opened_at, revoked_at, created_at
+		FROM journal_share_links
+		WHERE journal_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := config.DB.Query(ctx, query, journalID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch share links")})
+		return
+	}
+	defer rows.Close()
+
+	var links []gin.H
+
+	for rows.Next() {
+		var (
+			id           int
+			token        string
+			expiresAt    *time.Time
+			maxViews     *int
+			viewCount    int
+			lastOpenedAt *time.Time
+			revokedAt    *time.Time
+			createdAt    time.Time
+		)
+
+		if err := rows.Scan(&id, &token, &expiresAt, &maxViews, &viewCount, &lastOpenedAt, &revokedAt, &createdAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch share links")})
+			return
+		}
+
+		status := "active"
+		switch {
+		case revokedAt != nil:
+			status = "revoked"
+		case expiresAt != nil && time.Now().After(*expiresAt):
+			status = "expired"
+		case maxViews != nil && viewCount >= *maxViews:
+			status = "exhausted"
+		}
+
+		links = append(links, gin.H{
+			"id":             id,
+			"token":          token,
+			"url":            sharedJournalURL(token),
+			"status":         status,
+			"expires_at":     expiresAt,
+			"max_views":      maxViews,
+			"view_count":     viewCount,
+			"last_opened_at": lastOpenedAt,
+			"revoked_at":     revokedAt,
+			"created_at":     createdAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": links,
+	})
+}
+
+func RevokeShareLink(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	journalID, ok := ownJournal(ctx, c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	result, err := config.DB.Exec(
+		ctx,
+		`UPDATE journal_share_links SET revoked_at = COALESCE(revoked_at, NOW()) WHERE id = $1 AND journal_id = $2`,
+		c.Param("link_id"),
+		journalID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to revoke share link")})
+		return
+	}
+
+	if result.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "share link not found")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "share link revoked",
+	})
+}
+
+//
+// ===== OPEN SHARED JOURNAL (PUBLIC) =====
+//
+
+// GetSharedJournal opens a journal through its share token. Every call
+// counts as one open and is refused once the link is revoked, expired or
+// out of views. A journal that isn't published yet doesn't use up views.
+func GetSharedJournal(c *gin.Context) {
+	token := c.Param("token")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE journal_share_links SET
+			view_count = view_count + 1,
+			last_opened_at = NOW()
+		WHERE token = $1
+		  AND revoked_at IS NULL
+		  AND (expires_at IS NULL OR expires_at > NOW())
+		  AND (max_views IS NULL OR view_count < max_views)
+		  AND EXISTS (SELECT 1 FROM journals WHERE id = journal_id AND status = 'published')
+		RETURNING journal_id
+	`
+
+	var journalID int
+	if err := config.DB.QueryRow(ctx, query, token).Scan(&journalID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "share link is invalid or has expired")})
+		return
+	}
+
+	journalQuery := `
+		SELECT j.id, j.title, j.content, j.content_format, j.latitude, j.longitude, j.created_at,
+		       ` + profileSummaryColumns("u") + `,
+		       ` + engagementColumns("j", "$2") + `
+		FROM journals j
+		JOIN users u ON u.id = j.user_id
+		WHERE j.id = $1 AND j.status = 'published'
+	`
+
+	var (
+		id         int
+		title      string
+		content    string
+		format     string
+		lat        *float64
+		lng        *float64
+		createdAt  time.Time
+		author     profileSummary
+		engagement journalEngagement
+	)
+
+	targets := []any{
+		&id,
+		&title,
+		&content,
+		&format,
+		&lat,
+		&lng,
+		&createdAt,
+		&author.ID,
+		&author.Username,
+		&author.DisplayName,
+		&author.AvatarURL,
+	}
+	err := config.DB.QueryRow(ctx, journalQuery, journalID, c.GetInt("user_id")).
+		Scan(append(targets, engagement.scanTargets()...)...)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "journal not found")})
+		return
+	}
+
+	images, err := loadJournalImages(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch images")})
+		return
+	}
+
+	// gambar diakses lewat token juga, bukan lewat URL jurnal biasa
+	sharedImageURL := func(imageID int) string {
+		return fmt.Sprintf("%s/images/%d", sharedJournalURL(token), imageID)
+	}
+	for _, image := range images {
+		image["image_url"] = sharedImageURL(image["id"].(int))
+	}
+
+	rendered, err := services.RenderContent(format, content, sharedImageURL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to read journal")})
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	c.JSON(http.StatusOK, engagement.into(gin.H{
+		"id":         id,
+		"title":      title,
+		"content":    content,
+		"latitude":   lat,
+		"longitude":  lng,
+		"created_at": createdAt,
+		"author":     author,
+		"images":     images,
+
+		"content_format": format,
+		"content_html":   rendered.HTML,
+		"excerpt":        rendered.Excerpt,
+	}))
+}
+
+// ServeSharedJournalImage serves an image of a shared journal. Image loads
+// don't count as opens, but a link that has been revoked or has expired
+// stops working for images too. Once the link is out of views, images
+// keep loading for sharedImageGrace after the last open, so the page
+// that used the final view still shows them.
+func ServeSharedJournalImage(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var journal journalAccess
+	query := `
+		SELECT j.id, j.user_id, j.visibility, j.status
+		FROM journal_share_links l
+		JOIN journals j ON j.id = l.journal_id
+		WHERE l.token = $1
+		  AND j.status = 'published'
+		  AND l.revoked_at IS NULL
+		  AND (l.expires_at IS NULL OR l.expires_at > NOW())
+		  AND (l.max_views IS NULL OR l.view_count < l.max_views
+		       OR l.last_opened_at > NOW() - make_interval(secs => $2))
+	`
+
+	err := config.DB.QueryRow(ctx, query, c.Param("token"), sharedImageGrace.Seconds()).Scan(&journal.ID, &journal.OwnerID, &journal.Visibility, &journal.Status)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "share link is invalid or has expired")})
+		return
+	}
+
+	// jangan sampai di-cache publik walaupun jurnalnya public
+	journal.Visibility = VisibilityPrivate
+	serveJournalImageFile(ctx, c, journal, c.Param("image_id"))
+}
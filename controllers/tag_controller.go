@@ -0,0 +1,336 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"event-journal-backend/config"
+	"event-journal-backend/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// journalTagsSQL selects the tag slugs of the journal under alias as a
+// text[] column.
+func journalTagsSQL(alias string) string {
+	return "ARRAY(SELECT jtg.slug FROM journal_tags jt JOIN tags jtg ON jtg.id = jt.tag_id" +
+		" WHERE jt.journal_id = " + alias + ".id ORDER BY jtg.slug)"
+}
+
+// journalHasTagSQL is a WHERE condition that passes every journal when the
+// tag in tagParam is empty, and otherwise only journals carrying it.
+func journalHasTagSQL(alias, tagParam string) string {
+	return "(" + tagParam + " = '' OR EXISTS (SELECT 1 FROM journal_tags ft JOIN tags ftg ON ftg.id = ft.tag_id" +
+		" WHERE ft.journal_id = " + alias + ".id AND ftg.slug = " + tagParam + "))"
+}
+
+// tagQuery reads an optional ?tag= filter. It writes the 400 response
+// itself when the tag is malformed.
+func tagQuery(c *gin.Context) (string, bool) {
+	tag := c.Query("tag")
+	if tag == "" {
+		return "", true
+	}
+
+	slug, err := services.NormalizeTag(tag)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid tag")})
+		return "", false
+	}
+
+	return slug, true
+}
+
+// normalizeTagsInput normalizes tags from a request body, writing the 400
+// response itself when they are invalid.
+func normalizeTagsInput(c *gin.Context, tags []string) ([]string, bool) {
+	slugs, err := services.NormalizeTags(tags)
+	switch {
+	case errors.Is(err, services.ErrTooManyTags):
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "a journal can have at most 10 tags")})
+		return nil, false
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid tag")})
+		return nil, false
+	}
+
+	return slugs, true
+}
+
+// setJournalTags replaces the tags of a journal, creating new tags as
+// needed.
+func setJournalTags(ctx context.Context, tx pgx.Tx, journalID int, slugs []string) error {
+	_, err := tx.Exec(ctx, `INSERT INTO tags (slug) SELECT UNNEST($1::text[]) ON CONFLICT (slug) DO NOTHING`, slugs)
+	if err == nil {
+		_, err = tx.Exec(ctx, `DELETE FROM journal_tags WHERE journal_id = $1`, journalID)
+	}
+	if err == nil {
+		_, err = tx.Exec(
+			ctx,
+			`INSERT INTO journal_tags (journal_id, tag_id) SELECT $1, id FROM tags WHERE slug = ANY($2)`,
+			journalID,
+			slugs,
+		)
+	}
+	return err
+}
+
+// UpdateJournalTags replaces the tags of one of the user's journals.
+func UpdateJournalTags(c *gin.Context) {
+	var input struct {
+		Tags []string `json:"tags"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	slugs, ok := normalizeTagsInput(c, input.Tags)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	journalID, ok := ownJournal(ctx, c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to update tags")})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	err = setJournalTags(ctx, tx, journalID, slugs)
+	if err == nil {
+		// supaya perangkat lain ikut mengunduh tag baru saat sync
+		_, err = tx.Exec(ctx, `UPDATE journals SET updated_at = NOW() WHERE id = $1`, journalID)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to update tags")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":   journalID,
+		"tags": slugs,
+	})
+}
+
+//
+// ===== TAG AUTOCOMPLETE & TAG PAGES =====
+//
+
+// GetTags suggests tags starting with ?q=, most used on public journals
+// first. Without q it lists the most used tags.
+func GetTags(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	prefix := ""
+	if q := c.Query("q"); q != "" {
+		slug, err := services.NormalizeTag(q)
+		if err != nil {
+			c.JSON(http.StatusOK, gin.H{"data": []gin.H{}})
+			return
+		}
+		prefix = slug
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT t.slug, COUNT(j.id) AS journal_count
+		FROM tags t
+		LEFT JOIN journal_tags jt ON jt.tag_id = t.id
+		LEFT JOIN journals j ON j.id = jt.journal_id AND j.is_public = true
+		WHERE starts_with(t.slug, $1) -- bukan LIKE: '_' boleh ada di slug
+		GROUP BY t.id
+		HAVING $1 <> '' OR COUNT(j.id) > 0
+		ORDER BY journal_count DESC, t.slug ASC
+		LIMIT $2
+	`
+
+	rows, err := config.DB.Query(ctx, query, prefix, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch tags")})
+		return
+	}
+	defer rows.Close()
+
+	tags := []gin.H{}
+
+	for rows.Next() {
+		var slug string
+		var count int
+		if err := rows.Scan(&slug, &count); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch tags")})
+			return
+		}
+
+		tags = append(tags, gin.H{
+			"tag":           slug,
+			"journal_count": count,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": tags,
+	})
+}
+
+// GetTagPage lists public journals carrying the tag, plus approved events
+// in the category of the same name or with tagged journals.
+func GetTagPage(c *gin.Context) {
+	slug, err := services.NormalizeTag(c.Param("tag"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "tag not found")})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	offset := (page - 1) * limit
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var total int
+	countQuery := `
+		SELECT COUNT(*)
+		FROM journals j
+		WHERE j.is_public = true
+		  AND ` + journalHasTagSQL("j", "$1")
+	_ = config.DB.QueryRow(ctx, countQuery, slug).Scan(&total)
+
+	journalQuery := `
+		SELECT j.id, j.title, j.excerpt, j.latitude, j.longitude, j.created_at,
+		       ` + journalTagsSQL("j") + `,
+		       ` + engagementColumns("j", "$4") + `
+		FROM journals j
+		WHERE j.is_public = true
+		  AND ` + journalHasTagSQL("j", "$1") + `
+		ORDER BY j.created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := config.DB.Query(ctx, journalQuery, slug, limit, offset, c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch journals")})
+		return
+	}
+	defer rows.Close()
+
+	journals := []gin.H{}
+
+	for rows.Next() {
+		var id int
+		var title, excerpt string
+		var lat, lng *float64
+		var createdAt time.Time
+		var tags []string
+		var engagement journalEngagement
+
+		if err := rows.Scan(append([]any{&id, &title, &excerpt, &lat, &lng, &createdAt, &tags}, engagement.scanTargets()...)...); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to read journal")})
+			return
+		}
+
+		journals = append(journals, engagement.into(gin.H{
+			"id":         id,
+			"title":      title,
+			"excerpt":    excerpt,
+			"latitude":   lat,
+			"longitude":  lng,
+			"created_at": createdAt,
+			"tags":       tags,
+		}))
+	}
+
+	eventQuery := `
+		SELECT e.id, e.title, e.start_date, e.end_date, e.location_name,
+		       ` + eventCategoriesSQL("e") + `
+		FROM events e
+		WHERE e.status = 'approved'
+		  AND (
+		    EXISTS (
+		      SELECT 1 FROM event_categories ec
+		      JOIN categories cat ON cat.id = ec.category_id
+		      WHERE ec.event_id = e.id AND cat.slug = $1
+		    )
+		    OR EXISTS (
+		      SELECT 1 FROM journals j
+		      WHERE j.event_id = e.id AND j.is_public = true
+		        AND ` + journalHasTagSQL("j", "$1") + `
+		    )
+		  )
+		ORDER BY e.start_date DESC NULLS LAST
+		LIMIT 10
+	`
+
+	eventRows, err := config.DB.Query(ctx, eventQuery, slug)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch events")})
+		return
+	}
+	defer eventRows.Close()
+
+	events := []gin.H{}
+
+	for eventRows.Next() {
+		var id int
+		var title string
+		var startDate, endDate *time.Time
+		var locationName *string
+		var categories []string
+
+		if err := eventRows.Scan(&id, &title, &startDate, &endDate, &locationName, &categories); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch events")})
+			return
+		}
+
+		events = append(events, gin.H{
+			"id":            id,
+			"title":         title,
+			"start_date":    startDate,
+			"end_date":      endDate,
+			"location_name": locationName,
+			"categories":    categories,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tag":      slug,
+		"journals": journals,
+		"events":   events,
+		"pagination": gin.H{
+			"page":        page,
+			"limit":       limit,
+			"total":       total,
+			"total_pages": int(math.Ceil(float64(total) / float64(limit))),
+		},
+	})
+}
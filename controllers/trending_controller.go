@@ -0,0 +1,233 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"event-journal-backend/config"
+	"event-journal-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type trendingFilter struct {
+	Since  time.Time
+	Lat    *float64
+	Lng    *float64
+	Radius float64
+	Limit  int
+}
+
+// parseTrendingFilter reads ?window=24h|7d|30d, optional lat/lng/radius
+// and limit. It writes the 400 response itself when input is invalid.
+func parseTrendingFilter(c *gin.Context) (trendingFilter, bool) {
+	filter := trendingFilter{Radius: 10, Limit: 20}
+
+	window, ok := services.ParseTrendingWindow(c.Query("window"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid window")})
+		return filter, false
+	}
+	filter.Since = time.Now().Add(-window)
+
+	if c.Query("lat") != "" && c.Query("lng") != "" {
+		lat, errLat := strconv.ParseFloat(c.Query("lat"), 64)
+		lng, errLng := strconv.ParseFloat(c.Query("lng"), 64)
+		if errLat != nil || errLng != nil || !validCoordinates(lat, lng) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid coordinates")})
+			return filter, false
+		}
+		filter.Lat, filter.Lng = &lat, &lng
+	}
+
+	if radius, err := strconv.ParseFloat(c.Query("radius"), 64); err == nil && radius > 0 {
+		filter.Radius = radius
+	}
+
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil && limit > 0 && limit <= 50 {
+		filter.Limit = limit
+	}
+
+	return filter, true
+}
+
+//
+// ===== TRENDING JOURNALS =====
+//
+
+func GetTrendingJournals(c *gin.Context) {
+	filter, ok := parseTrendingFilter(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT
+			j.id, j.title, j.content, j.latitude, j.longitude, j.created_at,
+			` + services.DecayedScoreSQL("j", "$1") + ` AS score,
+			` + engagementColumns("j", "$7") + `,
+			` + profileSummaryColumns("u") + `
+		FROM journals j
+		JOIN users u ON u.id = j.user_id
+		WHERE j.is_public = true
+		  AND j.last_engaged_at >= $2
+		  AND j.popularity_score > 0
+		  AND (
+		    $3::float8 IS NULL
+		    OR (
+		      j.latitude IS NOT NULL
+		      AND j.longitude IS NOT NULL
+		      AND ` + services.DistanceKmSQL("$3", "$4", "j.latitude", "j.longitude") + ` <= $5
+		    )
+		  )
+		ORDER BY score DESC, j.id DESC
+		LIMIT $6
+	`
+
+	rows, err := config.DB.Query(
+		ctx,
+		query,
+		services.PopularityDecayRate(),
+		filter.Since,
+		filter.Lat,
+		filter.Lng,
+		filter.Radius,
+		filter.Limit,
+		c.GetInt("user_id"),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch journals")})
+		return
+	}
+	defer rows.Close()
+
+	var journals []gin.H
+
+	for rows.Next() {
+		var (
+			id        int
+			title     string
+			content   string
+			lat       *float64
+			lng       *float64
+			createdAt time.Time
+			score     float64
+
+			engagement journalEngagement
+			author     profileSummary
+		)
+
+		if err := rows.Scan(append(
+			append([]any{&id, &title, &content, &lat, &lng, &createdAt, &score}, engagement.scanTargets()...),
+			&author.ID,
+			&author.Username,
+			&author.DisplayName,
+			&author.AvatarURL,
+		)...); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to read journal")})
+			return
+		}
+
+		journals = append(journals, engagement.into(gin.H{
+			"id":               id,
+			"title":            title,
+			"content":          content,
+			"latitude":         lat,
+			"longitude":        lng,
+			"created_at":       createdAt,
+			"author":           author,
+			"popularity_score": score,
+		}))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": journals,
+	})
+}
+
+//
+// ===== TRENDING EVENTS =====
+//
+
+func GetTrendingEvents(c *gin.Context) {
+	filter, ok := parseTrendingFilter(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT
+			e.id, e.title, e.location_name, e.latitude, e.longitude,
+			e.start_date, e.end_date,
+			` + services.DecayedScoreSQL("e", "$1") + ` AS score
+		FROM events e
+		WHERE e.status = 'approved'
+		  AND e.last_engaged_at >= $2
+		  AND e.popularity_score > 0
+		  AND (
+		    $3::float8 IS NULL
+		    OR ` + services.DistanceKmSQL("$3", "$4", "e.latitude", "e.longitude") + ` <= $5
+		  )
+		ORDER BY score DESC, e.id DESC
+		LIMIT $6
+	`
+
+	rows, err := config.DB.Query(
+		ctx,
+		query,
+		services.PopularityDecayRate(),
+		filter.Since,
+		filter.Lat,
+		filter.Lng,
+		filter.Radius,
+		filter.Limit,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch events")})
+		return
+	}
+	defer rows.Close()
+
+	var events []gin.H
+
+	for rows.Next() {
+		var (
+			id           int
+			title        string
+			locationName *string
+			lat          float64
+			lng          float64
+			startDate    *time.Time
+			endDate      *time.Time
+			score        float64
+		)
+
+		if err := rows.Scan(&id, &title, &locationName, &lat, &lng, &startDate, &endDate, &score); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch events")})
+			return
+		}
+
+		events = append(events, gin.H{
+			"id":               id,
+			"title":            title,
+			"location_name":    locationName,
+			"latitude":         lat,
+			"longitude":        lng,
+			"start_date":       startDate,
+			"end_date":         endDate,
+			"popularity_score": score,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": events,
+	})
+}
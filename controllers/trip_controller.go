@@ -0,0 +1,705 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"event-journal-backend/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxTripTitleLength = 120
+
+type TripInput struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+	EventID     *int    `json:"event_id"` // 0 → lepas dari event
+	Visibility  *string `json:"visibility"`
+}
+
+// validateTripInput trims the title and checks visibility and the event,
+// writing the 400 response itself when the input is invalid.
+func validateTripInput(ctx context.Context, c *gin.Context, input *TripInput, requireTitle bool) bool {
+	if input.Title != nil {
+		title := strings.TrimSpace(*input.Title)
+		input.Title = &title
+	}
+
+	if (requireTitle && input.Title == nil) || (input.Title != nil && *input.Title == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "trip title is required")})
+		return false
+	}
+	if input.Title != nil && utf8.RuneCountInString(*input.Title) > maxTripTitleLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "trip title is too long")})
+		return false
+	}
+
+	if input.Visibility != nil && !validVisibility(*input.Visibility) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid visibility")})
+		return false
+	}
+
+	if input.EventID != nil && *input.EventID != 0 {
+		var exists bool
+		err := config.DB.QueryRow(
+			ctx,
+			`SELECT EXISTS (SELECT 1 FROM events WHERE id = $1 AND event_type = 'organizer')`,
+			*input.EventID,
+		).Scan(&exists)
+
+		if err != nil || !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "invalid event_id")})
+			return false
+		}
+	}
+
+	return true
+}
+
+// tripVisibleSQL is journalVisibleSQL for trips under alias.
+func tripVisibleSQL(alias, viewerParam string) string {
+	return "(" + alias + ".visibility IN ('public', 'unlisted')" +
+		" OR " + alias + ".user_id = " + viewerParam +
+		" OR (" + alias + ".visibility = 'followers' AND EXISTS (" +
+		"SELECT 1 FROM user_follows tf WHERE tf.follower_id = " + viewerParam +
+		" AND tf.followee_id = " + alias + ".user_id)))"
+}
+
+type tripRow struct {
+	ID          int
+	OwnerID     int
+	EventID     *int
+	Title       string
+	Description string
+	Visibility  string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	// rentang event (multi-hari) kalau trip terhubung ke event
+	EventTitle     *string
+	EventStartDate *time.Time
+	EventEndDate   *time.Time
+}
+
+const tripColumns = `
+	t.id, t.user_id, t.event_id, t.title, t.description, t.visibility, t.created_at, t.updated_at,
+	e.title, e.start_date, e.end_date
+`
+
+func (t *tripRow) scanTargets() []any {
+	return []any{
+		&t.ID, &t.OwnerID, &t.EventID, &t.Title, &t.Description, &t.Visibility, &t.CreatedAt, &t.UpdatedAt,
+		&t.EventTitle, &t.EventStartDate, &t.EventEndDate,
+	}
+}
+
+func (t tripRow) toJSON() gin.H {
+	trip := gin.H{
+		"id":          t.ID,
+		"title":       t.Title,
+		"description": t.Description,
+		"visibility":  t.Visibility,
+		"event":       nil,
+		"created_at":  t.CreatedAt,
+		"updated_at":  t.UpdatedAt,
+	}
+	if t.EventID != nil {
+		trip["event"] = gin.H{
+			"id":         *t.EventID,
+			"title":      t.EventTitle,
+			"start_date": t.EventStartDate,
+			"end_date":   t.EventEndDate,
+		}
+	}
+	return trip
+}
+
+// findVisibleTrip loads tripID for the current viewer, writing the 404/403
+// response itself and returning false when it can't be seen.
+func findVisibleTrip(ctx context.Context, c *gin.Context, tripID string) (tripRow, bool) {
+	var trip tripRow
+	var visible bool
+
+	query := `
+		SELECT ` + tripColumns + `, ` + tripVisibleSQL("t", "$2") + `
+		FROM trips t
+		LEFT JOIN events e ON e.id = t.event_id
+		WHERE t.id = $1
+	`
+
+	err := config.DB.QueryRow(ctx, query, tripID, c.GetInt("user_id")).
+		Scan(append(trip.scanTargets(), &visible)...)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "trip not found")})
+		return trip, false
+	}
+
+	if !visible {
+		c.JSON(http.StatusForbidden, gin.H{"error": tr(c, "this trip is private")})
+		return trip, false
+	}
+
+	return trip, true
+}
+
+// ownTrip is findVisibleTrip for changes: only the author passes.
+func ownTrip(ctx context.Context, c *gin.Context, tripID string) (tripRow, bool) {
+	trip, ok := findVisibleTrip(ctx, c, tripID)
+	if ok && trip.OwnerID != c.GetInt("user_id") {
+		c.JSON(http.StatusForbidden, gin.H{"error": tr(c, "not allowed")})
+		return trip, false
+	}
+	return trip, ok
+}
+
+type tripEntry struct {
+	JournalID  int
+	Title      string
+	Excerpt    string
+	Latitude   *float64
+	Longitude  *float64
+	Position   int
+	OccurredAt time.Time
+	Engagement journalEngagement
+}
+
+// loadTripEntries returns the trip's journals the viewer may list, in trip
+// order.
+func loadTripEntries(ctx context.Context, tripID, viewerID int) ([]tripEntry, error) {
+	query := `
+		SELECT j.id, j.title, j.excerpt, j.latitude, j.longitude,
+		       COALESCE(j.trip_position, 0), COALESCE(j.occurred_at, j.created_at),
+		       ` + engagementColumns("j", "$2") + `
+		FROM journals j
+		WHERE j.trip_id = $1
+		  AND ` + journalListedSQL("j", "$2") + `
+		ORDER BY j.trip_position ASC NULLS LAST, COALESCE(j.occurred_at, j.created_at) ASC, j.id ASC
+	`
+
+	rows, err := config.DB.Query(ctx, query, tripID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []tripEntry{}
+
+	for rows.Next() {
+		var e tripEntry
+		targets := []any{&e.JournalID, &e.Title, &e.Excerpt, &e.Latitude, &e.Longitude, &e.Position, &e.OccurredAt}
+		if err := rows.Scan(append(targets, e.Engagement.scanTargets()...)...); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+func (e tripEntry) toJSON() gin.H {
+	return e.Engagement.into(gin.H{
+		"journal_id":  e.JournalID,
+		"title":       e.Title,
+		"excerpt":     e.Excerpt,
+		"latitude":    e.Latitude,
+		"longitude":   e.Longitude,
+		"position":    e.Position,
+		"occurred_at": e.OccurredAt,
+	})
+}
+
+func (e tripEntry) hasLocation() bool {
+	return e.Latitude != nil && e.Longitude != nil && *e.Latitude != 0 && *e.Longitude != 0
+}
+
+//
+// ===== TRIPS =====
+//
+
+func CreateTrip(c *gin.Context) {
+	var input TripInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if !validateTripInput(ctx, c, &input, true) {
+		return
+	}
+
+	if input.Visibility == nil {
+		private := VisibilityPrivate
+		input.Visibility = &private
+	}
+	if input.EventID != nil && *input.EventID == 0 {
+		input.EventID = nil
+	}
+
+	query := `
+		INSERT INTO trips (user_id, event_id, title, description, visibility)
+		VALUES ($1, $2, $3, COALESCE($4, ''), $5)
+		RETURNING id
+	`
+
+	var id int
+	err := config.DB.QueryRow(
+		ctx,
+		query,
+		c.GetInt("user_id"),
+		input.EventID,
+		*input.Title,
+		input.Description,
+		*input.Visibility,
+	).Scan(&id)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to create trip")})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"id":      id,
+		"message": "trip created",
+	})
+}
+
+func GetMyTrips(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT ` + tripColumns + `,
+		       (SELECT COUNT(*) FROM journals j WHERE j.trip_id = t.id)
+		FROM trips t
+		LEFT JOIN events e ON e.id = t.event_id
+		WHERE t.user_id = $1
+		ORDER BY t.updated_at DESC
+	`
+
+	rows, err := config.DB.Query(ctx, query, c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch trips")})
+		return
+	}
+	defer rows.Close()
+
+	var trips []gin.H
+
+	for rows.Next() {
+		var trip tripRow
+		var entryCount int
+
+		if err := rows.Scan(append(trip.scanTargets(), &entryCount)...); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch trips")})
+			return
+		}
+
+		row := trip.toJSON()
+		row["entry_count"] = entryCount
+		trips = append(trips, row)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": trips,
+	})
+}
+
+func GetTrip(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	trip, ok := findVisibleTrip(ctx, c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	entries, err := loadTripEntries(ctx, trip.ID, c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch trip entries")})
+		return
+	}
+
+	var author profileSummary
+	err = config.DB.QueryRow(
+		ctx,
+		`SELECT `+profileSummaryColumns("u")+` FROM users u WHERE u.id = $1`,
+		trip.OwnerID,
+	).Scan(&author.ID, &author.Username, &author.DisplayName, &author.AvatarURL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch trip")})
+		return
+	}
+
+	items := make([]gin.H, 0, len(entries))
+	for _, e := range entries {
+		items = append(items, e.toJSON())
+	}
+
+	response := trip.toJSON()
+	response["author"] = author
+	response["entries"] = items
+	if len(entries) > 0 {
+		// rentang waktu trip diambil dari entri pertama & terakhir
+		first, last := entries[0].OccurredAt, entries[0].OccurredAt
+		for _, e := range entries[1:] {
+			first = minTime(first, e.OccurredAt)
+			last = maxTime(last, e.OccurredAt)
+		}
+		response["started_at"] = first
+		response["ended_at"] = last
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+func UpdateTrip(c *gin.Context) {
+	var input TripInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	trip, ok := ownTrip(ctx, c, c.Param("id"))
+	if !ok || !validateTripInput(ctx, c, &input, false) {
+		return
+	}
+
+	query := `
+		UPDATE trips SET
+			title = COALESCE($2, title),
+			description = COALESCE($3, description),
+			visibility = COALESCE($4, visibility),
+			event_id = CASE WHEN $5::int IS NULL THEN event_id ELSE NULLIF($5, 0) END,
+			updated_at = NOW()
+		WHERE id = $1
+	`
+
+	_, err := config.DB.Exec(ctx, query, trip.ID, input.Title, input.Description, input.Visibility, input.EventID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to update trip")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "trip updated",
+	})
+}
+
+// DeleteTrip removes the trip only; its journals stay as normal journals.
+func DeleteTrip(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	trip, ok := ownTrip(ctx, c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	if _, err := config.DB.Exec(ctx, `DELETE FROM trips WHERE id = $1`, trip.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to delete trip")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "trip deleted",
+	})
+}
+
+//
+// ===== TRIP ENTRIES =====
+//
+
+type TripEntryInput struct {
+	JournalID  int        `json:"journal_id" binding:"required"`
+	OccurredAt *time.Time `json:"occurred_at"`
+	Position   *int       `json:"position"` // kosong → di akhir
+}
+
+// AddTripEntry puts one of the author's journals into the trip, or moves
+// it within the trip when it is already there. An explicit position
+// inserts the entry there and moves the entries from that position on one
+// place down, so no two entries share a position.
+func AddTripEntry(c *gin.Context) {
+	var input TripEntryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if input.Position != nil && *input.Position < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "position must be at least 1")})
+		return
+	}
+
+	trip, ok := ownTrip(ctx, c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to update trip")})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// kunci baris trip supaya penambahan entri yang bersamaan tidak
+	// menghasilkan posisi yang sama
+	if _, err := tx.Exec(ctx, `UPDATE trips SET updated_at = NOW() WHERE id = $1`, trip.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to update trip")})
+		return
+	}
+
+	var createdAt time.Time
+	err = tx.QueryRow(
+		ctx,
+		`SELECT created_at FROM journals WHERE id = $1 AND user_id = $2 FOR UPDATE`,
+		input.JournalID,
+		trip.OwnerID,
+	).Scan(&createdAt)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "journal not found")})
+		return
+	}
+
+	// occurred_at ikut menentukan check-in, jadi dibatasi sama seperti sync
+	if input.OccurredAt != nil {
+		occurredAt, problem := checkOccurredAt(*input.OccurredAt, createdAt)
+		if problem != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, problem)})
+			return
+		}
+		input.OccurredAt = &occurredAt
+	}
+
+	if input.Position != nil {
+		_, err = tx.Exec(
+			ctx,
+			`UPDATE journals SET trip_position = trip_position + 1 WHERE trip_id = $1 AND id <> $2 AND trip_position >= $3`,
+			trip.ID,
+			input.JournalID,
+			*input.Position,
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to update trip")})
+			return
+		}
+	}
+
+	query := `
+		UPDATE journals SET
+			trip_id = $2,
+			trip_position = COALESCE($3, (
+				SELECT COALESCE(MAX(trip_position), 0) + 1
+				FROM journals WHERE trip_id = $2 AND id <> $1
+			)),
+			occurred_at = COALESCE($4, occurred_at)
+		WHERE id = $1
+		RETURNING trip_position
+	`
+
+	var position int
+	err = tx.QueryRow(ctx, query, input.JournalID, trip.ID, input.Position, input.OccurredAt).Scan(&position)
+	if err == nil && input.OccurredAt != nil {
+		_, err = checkInJournal(ctx, tx, input.JournalID)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to update trip")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"journal_id":  input.JournalID,
+		"trip_id":     trip.ID,
+		"position":    position,
+		"occurred_at": input.OccurredAt,
+	})
+}
+
+func RemoveTripEntry(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	trip, ok := ownTrip(ctx, c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	result, err := config.DB.Exec(
+		ctx,
+		`UPDATE journals SET trip_id = NULL, trip_position = NULL WHERE id = $1 AND trip_id = $2`,
+		c.Param("journal_id"),
+		trip.ID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to update trip")})
+		return
+	}
+
+	if result.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": tr(c, "journal is not in this trip")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "journal removed from trip",
+	})
+}
+
+// ReorderTripEntries sets the trip order from a full list of journal ids.
+func ReorderTripEntries(c *gin.Context) {
+	var input struct {
+		JournalIDs []int `json:"journal_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	trip, ok := ownTrip(ctx, c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to update trip")})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var inTrip int
+	err = tx.QueryRow(ctx, `SELECT COUNT(*) FROM journals WHERE trip_id = $1`, trip.ID).Scan(&inTrip)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to update trip")})
+		return
+	}
+
+	// urutan baru harus memuat semua entri, masing-masing sekali
+	query := `
+		UPDATE journals j SET trip_position = o.position
+		FROM UNNEST($2::int[]) WITH ORDINALITY AS o(id, position)
+		WHERE j.id = o.id AND j.trip_id = $1
+	`
+
+	result, err := tx.Exec(ctx, query, trip.ID, input.JournalIDs)
+	if err == nil && (int(result.RowsAffected()) != inTrip || len(input.JournalIDs) != inTrip) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": tr(c, "journal_ids must list every entry of the trip once")})
+		return
+	}
+	if err == nil {
+		_, err = tx.Exec(ctx, `UPDATE trips SET updated_at = NOW() WHERE id = $1`, trip.ID)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to update trip")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "trip reordered",
+	})
+}
+
+// GetTripRoute returns the trip as GeoJSON: a LineString through the
+// entries in trip order plus a Point marker per entry. Entries without a
+// location are left out.
+func GetTripRoute(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	trip, ok := findVisibleTrip(ctx, c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	entries, err := loadTripEntries(ctx, trip.ID, c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": tr(c, "failed to fetch trip entries")})
+		return
+	}
+
+	// GeoJSON pakai urutan [longitude, latitude]
+	var line [][]float64
+	markers := []gin.H{}
+
+	for _, e := range entries {
+		if !e.hasLocation() {
+			continue
+		}
+
+		point := []float64{*e.Longitude, *e.Latitude}
+		line = append(line, point)
+
+		markers = append(markers, gin.H{
+			"type": "Feature",
+			"geometry": gin.H{
+				"type":        "Point",
+				"coordinates": point,
+			},
+			"properties": gin.H{
+				"journal_id":  e.JournalID,
+				"title":       e.Title,
+				"excerpt":     e.Excerpt,
+				"position":    e.Position,
+				"occurred_at": e.OccurredAt,
+			},
+		})
+	}
+
+	features := markers
+	// LineString butuh minimal dua titik
+	if len(line) >= 2 {
+		features = append([]gin.H{{
+			"type": "Feature",
+			"geometry": gin.H{
+				"type":        "LineString",
+				"coordinates": line,
+			},
+			"properties": gin.H{
+				"trip_id": trip.ID,
+				"title":   trip.Title,
+			},
+		}}, markers...)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"type":     "FeatureCollection",
+		"features": features,
+	})
+}
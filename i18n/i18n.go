@@ -0,0 +1,128 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+const Default = "en"
+
+var Supported = []string{"en", "id"}
+
+var matcher = language.NewMatcher([]language.Tag{
+	language.English,
+	language.Indonesian,
+})
+
+// Catalog maps locale -> message key -> translated text.
+type Catalog map[string]map[string]string
+
+// LoadCatalog reads <locale>.json files from dir.
+func LoadCatalog(fsys fs.FS, dir string) (Catalog, error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := Catalog{}
+	for _, file := range files {
+		raw, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		messages := map[string]string{}
+		if err := json.Unmarshal(raw, &messages); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+
+		catalog[strings.TrimSuffix(path.Base(file), ".json")] = messages
+	}
+
+	return catalog, nil
+}
+
+func MustLoadCatalog(fsys fs.FS, dir string) Catalog {
+	catalog, err := LoadCatalog(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+
+	return catalog
+}
+
+// T translates key, falling back to the default locale and then to the
+// key itself, so English messages can be used directly as keys.
+func (c Catalog) T(locale, key string, args ...any) string {
+	text, ok := c[Normalize(locale)][key]
+	if !ok {
+		text, ok = c[Default][key]
+	}
+	if !ok {
+		text = key
+	}
+
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+
+	return text
+}
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// messages holds API error messages and push notification texts.
+var messages = MustLoadCatalog(localeFiles, "locales")
+
+func T(locale, key string, args ...any) string {
+	return messages.T(locale, key, args...)
+}
+
+// Normalize maps a language tag such as "id-ID" to a supported locale,
+// or returns Default.
+func Normalize(locale string) string {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return Default
+	}
+
+	_, index, confidence := matcher.Match(tag)
+	if confidence == language.No {
+		return Default
+	}
+
+	return Supported[index]
+}
+
+// IsSupported reports whether locale names one of the Supported locales.
+func IsSupported(locale string) bool {
+	for _, l := range Supported {
+		if l == locale {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Negotiate picks the best supported locale for an Accept-Language header.
+func Negotiate(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return Default
+	}
+
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return Default
+	}
+
+	return Supported[index]
+}
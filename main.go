@@ -14,11 +14,19 @@ import (
 func main() {
 	godotenv.Load()
 	config.ConnectDB()
-	services.InitFirebase()
+	services.InitPush()
+	services.InitMailer()
+	services.StartDigestScheduler()
+	services.StartEngagementReconciler()
+	services.StartJournalPublisher()
+	services.StartSyncTombstoneCleaner()
+	services.StartIdempotencyKeyCleaner()
 
 	r := gin.Default()
 
-	r.Static("/uploads", "./uploads")
+	// gambar jurnal dilayani lewat /api/journals/:id/images/:image_id
+	// supaya visibility jurnal tetap berlaku
+	r.Static("/uploads/avatars", "./uploads/avatars")
 	routes.SetupRoutes(r)
 
 	log.Fatal(r.Run(":8080"))
@@ -15,7 +15,7 @@ func AdminOnly() gin.HandlerFunc {
 
 		if role != "admin" {
 			c.JSON(http.StatusForbidden, gin.H{
-				"error": "admin access only",
+				"error": errorMessage(c, "admin access only"),
 			})
 			c.Abort()
 			return
@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"event-journal-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxIdempotencyKeyLength = 255
+
+	// maxIdempotentBodySize bounds the body read into memory for the
+	// fingerprint; a full sync batch fits comfortably.
+	maxIdempotentBodySize = 8 << 20
+)
+
+// idempotencyRecorder keeps a copy of the response body while it is
+// written to the client.
+type idempotencyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *idempotencyRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *idempotencyRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
+
+// Idempotency makes retries of a mutating request safe. When the client
+// sends an Idempotency-Key header, the first response for that user and
+// key is stored and replayed for every retry, marked with an
+// Idempotent-Replayed header. Reusing a key for a different request
+// (method, path or body) is refused with 422; a retry that arrives while
+// the first request is still running gets 409. Server errors are not
+// stored, so the client can retry them with the same key.
+//
+// It must come after JWTAuthMiddleware; requests without the header, safe
+// methods and anonymous requests pass through untouched. Multipart uploads
+// do too: clients pick a new boundary on every retry, so the raw body
+// can't be fingerprinted, and upload routes dedupe by client_id instead.
+func Idempotency() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		userID := c.GetInt("user_id")
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		if key == "" || userID == 0 || strings.HasPrefix(c.ContentType(), "multipart/") {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(c, "invalid Idempotency-Key")})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodySize))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": errorMessage(c, "request body too large")})
+			c.Abort()
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errorMessage(c, "failed to read request body")})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+		hash.Write(body)
+		fingerprint := hex.EncodeToString(hash.Sum(nil))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		claimed, record, err := services.ClaimIdempotencyKey(ctx, userID, key, fingerprint)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage(c, "failed to check Idempotency-Key")})
+			c.Abort()
+			return
+		}
+
+		if !claimed {
+			switch {
+			case record.Fingerprint != fingerprint:
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": errorMessage(c, "Idempotency-Key was already used for a different request")})
+			case !record.Completed:
+				c.JSON(http.StatusConflict, gin.H{"error": errorMessage(c, "a request with this Idempotency-Key is still in progress")})
+			default:
+				c.Header("Idempotent-Replayed", "true")
+				c.Data(record.StatusCode, record.ContentType, record.Body)
+			}
+			c.Abort()
+			return
+		}
+
+		recorder := &idempotencyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		completed := false
+		defer func() {
+			// handler panic atau error server → klaim dilepas supaya bisa dicoba lagi
+			if completed {
+				return
+			}
+			if err := services.ReleaseIdempotencyKey(context.Background(), userID, key, fingerprint); err != nil {
+				log.Println("Failed to release idempotency key:", err)
+			}
+		}()
+
+		c.Next()
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+
+		err = services.CompleteIdempotencyKey(
+			context.Background(),
+			userID,
+			key,
+			fingerprint,
+			status,
+			recorder.Header().Get("Content-Type"),
+			recorder.body.Bytes(),
+		)
+		if err != nil {
+			log.Println("Failed to store idempotent response:", err)
+			return
+		}
+		completed = true
+	}
+}
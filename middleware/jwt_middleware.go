@@ -14,7 +14,7 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "authorization header required"})
+			c.JSON(http.StatusUnauthorized, gin.H{"error": errorMessage(c, "authorization header required")})
 			c.Abort()
 			return
 		}
@@ -26,7 +26,7 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 		})
 
 		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+			c.JSON(http.StatusUnauthorized, gin.H{"error": errorMessage(c, "invalid token")})
 			c.Abort()
 			return
 		}
@@ -36,7 +36,7 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 		// SAFE PARSING
 		userIDFloat, ok := claims["user_id"].(float64)
 		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token payload"})
+			c.JSON(http.StatusUnauthorized, gin.H{"error": errorMessage(c, "invalid token payload")})
 			c.Abort()
 			return
 		}
@@ -0,0 +1,13 @@
+package middleware
+
+import (
+	"event-journal-backend/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errorMessage translates an error using Accept-Language; middleware runs
+// before the user is known, so the saved preference can't be used here.
+func errorMessage(c *gin.Context, msg string) string {
+	return i18n.T(i18n.Negotiate(c.GetHeader("Accept-Language")), msg)
+}
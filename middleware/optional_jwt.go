@@ -31,6 +31,9 @@ func OptionalJWT() gin.HandlerFunc {
 		if err == nil && token.Valid {
 			if claims, ok := token.Claims.(jwt.MapClaims); ok {
 				c.Set("user_id", int(claims["user_id"].(float64)))
+				if role, ok := claims["role"].(string); ok {
+					c.Set("role", role)
+				}
 			}
 		}
 
@@ -0,0 +1,18 @@
+-- Home area and followed locations used to geo-target new event pushes.
+
+ALTER TABLE event_journal.users
+	ADD COLUMN IF NOT EXISTS home_latitude DOUBLE PRECISION,
+	ADD COLUMN IF NOT EXISTS home_longitude DOUBLE PRECISION,
+	ADD COLUMN IF NOT EXISTS home_location_name TEXT;
+
+CREATE TABLE IF NOT EXISTS event_journal.user_followed_locations (
+	id SERIAL PRIMARY KEY,
+	user_id INT NOT NULL REFERENCES event_journal.users(id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	latitude DOUBLE PRECISION NOT NULL,
+	longitude DOUBLE PRECISION NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_followed_locations_user
+	ON event_journal.user_followed_locations(user_id);
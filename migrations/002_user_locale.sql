@@ -0,0 +1,6 @@
+-- Preferred language for emails, push notifications and API messages.
+-- NULL means no preference yet: API messages follow Accept-Language and
+-- emails/pushes use the default language until the user picks one.
+
+ALTER TABLE event_journal.users
+	ADD COLUMN IF NOT EXISTS locale TEXT;
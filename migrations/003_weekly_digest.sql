@@ -0,0 +1,5 @@
+-- Opt-in weekly digest emails.
+
+ALTER TABLE event_journal.users
+	ADD COLUMN IF NOT EXISTS digest_opt_in BOOLEAN NOT NULL DEFAULT FALSE,
+	ADD COLUMN IF NOT EXISTS last_digest_sent_at TIMESTAMPTZ;
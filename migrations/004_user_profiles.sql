@@ -0,0 +1,17 @@
+-- Public user profiles. Authors are exposed by username instead of email.
+
+ALTER TABLE event_journal.users
+	ADD COLUMN IF NOT EXISTS username TEXT,
+	ADD COLUMN IF NOT EXISTS display_name TEXT,
+	ADD COLUMN IF NOT EXISTS bio TEXT,
+	ADD COLUMN IF NOT EXISTS avatar_url TEXT;
+
+UPDATE event_journal.users
+SET username = 'user' || id
+WHERE username IS NULL;
+
+ALTER TABLE event_journal.users
+	ALTER COLUMN username SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username_lower
+	ON event_journal.users(LOWER(username));
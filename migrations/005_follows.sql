@@ -0,0 +1,22 @@
+-- Social graph: users following users and events.
+
+CREATE TABLE IF NOT EXISTS event_journal.user_follows (
+	follower_id INT NOT NULL REFERENCES event_journal.users(id) ON DELETE CASCADE,
+	followee_id INT NOT NULL REFERENCES event_journal.users(id) ON DELETE CASCADE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (follower_id, followee_id),
+	CHECK (follower_id <> followee_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_follows_followee
+	ON event_journal.user_follows(followee_id);
+
+CREATE TABLE IF NOT EXISTS event_journal.event_follows (
+	user_id INT NOT NULL REFERENCES event_journal.users(id) ON DELETE CASCADE,
+	event_id INT NOT NULL REFERENCES event_journal.events(id) ON DELETE CASCADE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (user_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_follows_event
+	ON event_journal.event_follows(event_id);
@@ -0,0 +1,25 @@
+-- Time-decayed popularity. popularity_score is stored decayed to
+-- popularity_updated_at and decayed further at read time.
+
+ALTER TABLE event_journal.journals
+	ADD COLUMN IF NOT EXISTS view_count INT NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS popularity_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS popularity_updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+ALTER TABLE event_journal.events
+	ADD COLUMN IF NOT EXISTS popularity_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS popularity_updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE INDEX IF NOT EXISTS idx_journals_popularity_updated
+	ON event_journal.journals(popularity_updated_at);
+
+CREATE INDEX IF NOT EXISTS idx_events_popularity_updated
+	ON event_journal.events(popularity_updated_at);
+
+-- Seed from existing engagement so trending is not empty on day one.
+UPDATE event_journal.journals j SET
+	popularity_score =
+		3 * (SELECT COUNT(*) FROM event_journal.journal_likes l WHERE l.journal_id = j.id)
+		+ 5 * (SELECT COUNT(*) FROM event_journal.comments c WHERE c.journal_id = j.id)
+		+ 4 * (SELECT COUNT(*) FROM event_journal.bookmarks b WHERE b.journal_id = j.id),
+	popularity_updated_at = NOW();
@@ -0,0 +1,12 @@
+-- Denormalized engagement counters, maintained by the handlers and
+-- reconciled periodically by the engagement-counters job.
+
+ALTER TABLE event_journal.journals
+	ADD COLUMN IF NOT EXISTS like_count INT NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS comment_count INT NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS bookmark_count INT NOT NULL DEFAULT 0;
+
+UPDATE event_journal.journals j SET
+	like_count = (SELECT COUNT(*) FROM event_journal.journal_likes l WHERE l.journal_id = j.id),
+	comment_count = (SELECT COUNT(*) FROM event_journal.comments c WHERE c.journal_id = j.id),
+	bookmark_count = (SELECT COUNT(*) FROM event_journal.bookmarks b WHERE b.journal_id = j.id);
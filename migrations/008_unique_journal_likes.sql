@@ -0,0 +1,14 @@
+-- One like per user per journal, so PUT /journals/:id/like can rely on
+-- ON CONFLICT instead of a check-then-insert.
+
+DELETE FROM event_journal.journal_likes a
+USING event_journal.journal_likes b
+WHERE a.user_id = b.user_id
+  AND a.journal_id = b.journal_id
+  AND a.ctid > b.ctid;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_journal_likes_user_journal
+	ON event_journal.journal_likes(user_id, journal_id);
+
+UPDATE event_journal.journals j SET
+	like_count = (SELECT COUNT(*) FROM event_journal.journal_likes l WHERE l.journal_id = j.id);
@@ -0,0 +1,28 @@
+-- Threaded comments: replies, edit history, mentions and soft delete.
+
+ALTER TABLE event_journal.comments
+	ADD COLUMN IF NOT EXISTS parent_id INT REFERENCES event_journal.comments(id) ON DELETE CASCADE,
+	ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ,
+	ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ,
+	ADD COLUMN IF NOT EXISTS deleted_by INT REFERENCES event_journal.users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_comments_parent
+	ON event_journal.comments(parent_id);
+
+CREATE TABLE IF NOT EXISTS event_journal.comment_revisions (
+	id SERIAL PRIMARY KEY,
+	comment_id INT NOT NULL REFERENCES event_journal.comments(id) ON DELETE CASCADE,
+	content TEXT NOT NULL,
+	edited_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_comment_revisions_comment
+	ON event_journal.comment_revisions(comment_id);
+
+-- Who has already been notified for a comment, so edits don't re-notify.
+CREATE TABLE IF NOT EXISTS event_journal.comment_mentions (
+	comment_id INT NOT NULL REFERENCES event_journal.comments(id) ON DELETE CASCADE,
+	user_id INT NOT NULL REFERENCES event_journal.users(id) ON DELETE CASCADE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (comment_id, user_id)
+);
@@ -0,0 +1,18 @@
+-- Emoji reactions on journals and comments. A user may leave several
+-- different reactions on the same item, each at most once.
+
+CREATE TABLE IF NOT EXISTS event_journal.journal_reactions (
+	journal_id INT NOT NULL REFERENCES event_journal.journals(id) ON DELETE CASCADE,
+	user_id INT NOT NULL REFERENCES event_journal.users(id) ON DELETE CASCADE,
+	reaction TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (journal_id, user_id, reaction)
+);
+
+CREATE TABLE IF NOT EXISTS event_journal.comment_reactions (
+	comment_id INT NOT NULL REFERENCES event_journal.comments(id) ON DELETE CASCADE,
+	user_id INT NOT NULL REFERENCES event_journal.users(id) ON DELETE CASCADE,
+	reaction TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (comment_id, user_id, reaction)
+);
@@ -0,0 +1,32 @@
+-- Named bookmark collections with per-bookmark notes. Bookmarks without a
+-- collection stay in the user's default "all bookmarks" list.
+
+CREATE TABLE IF NOT EXISTS event_journal.bookmark_collections (
+	id SERIAL PRIMARY KEY,
+	user_id INT NOT NULL REFERENCES event_journal.users(id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	description TEXT,
+	is_public BOOLEAN NOT NULL DEFAULT false,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bookmark_collections_user_name
+	ON event_journal.bookmark_collections(user_id, LOWER(name));
+
+ALTER TABLE event_journal.bookmarks
+	ADD COLUMN IF NOT EXISTS collection_id INT REFERENCES event_journal.bookmark_collections(id) ON DELETE SET NULL,
+	ADD COLUMN IF NOT EXISTS note TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_bookmarks_collection
+	ON event_journal.bookmarks(collection_id);
+
+-- One bookmark per user per journal so POST /bookmarks can upsert.
+DELETE FROM event_journal.bookmarks a
+USING event_journal.bookmarks b
+WHERE a.user_id = b.user_id
+  AND a.journal_id = b.journal_id
+  AND a.ctid > b.ctid;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bookmarks_user_journal
+	ON event_journal.bookmarks(user_id, journal_id);
@@ -0,0 +1,22 @@
+-- Journal visibility levels. is_public becomes a generated column meaning
+-- "listed publicly", so existing public listings keep working unchanged.
+--
+--   private    only the author
+--   followers  the author and users following them
+--   unlisted   anyone with the link, never listed
+--   public     everyone, listed on map/feed/search
+
+ALTER TABLE event_journal.journals
+	ADD COLUMN IF NOT EXISTS visibility TEXT NOT NULL DEFAULT 'private'
+		CHECK (visibility IN ('private', 'followers', 'unlisted', 'public'));
+
+UPDATE event_journal.journals
+SET visibility = CASE WHEN is_public THEN 'public' ELSE 'private' END;
+
+ALTER TABLE event_journal.journals DROP COLUMN is_public;
+
+ALTER TABLE event_journal.journals
+	ADD COLUMN is_public BOOLEAN GENERATED ALWAYS AS (visibility = 'public') STORED;
+
+CREATE INDEX IF NOT EXISTS idx_journals_visibility
+	ON event_journal.journals(visibility);
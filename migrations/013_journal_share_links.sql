@@ -0,0 +1,16 @@
+-- Revocable share links that open a journal regardless of its visibility.
+
+CREATE TABLE IF NOT EXISTS event_journal.journal_share_links (
+	id SERIAL PRIMARY KEY,
+	journal_id INT NOT NULL REFERENCES event_journal.journals(id) ON DELETE CASCADE,
+	token TEXT NOT NULL UNIQUE,
+	expires_at TIMESTAMPTZ,
+	max_views INT,
+	view_count INT NOT NULL DEFAULT 0,
+	last_opened_at TIMESTAMPTZ,
+	revoked_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_journal_share_links_journal
+	ON event_journal.journal_share_links(journal_id);
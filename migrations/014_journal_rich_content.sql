@@ -0,0 +1,21 @@
+-- Journal content can be plain text, Markdown or a block document. The
+-- sanitized HTML and a plain-text excerpt are rendered when it is saved.
+
+ALTER TABLE event_journal.journals
+	ADD COLUMN IF NOT EXISTS content_format TEXT NOT NULL DEFAULT 'plain'
+		CHECK (content_format IN ('plain', 'markdown', 'blocks')),
+	ADD COLUMN IF NOT EXISTS content_html TEXT NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS excerpt TEXT NOT NULL DEFAULT '';
+
+-- jurnal lama semuanya plain text
+UPDATE event_journal.journals
+SET content_html = '<p>' || REPLACE(
+		REPLACE(REPLACE(REPLACE(REPLACE(COALESCE(content, ''),
+			'&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&#34;'),
+		E'\n', '<br>') || '</p>',
+	excerpt = REGEXP_REPLACE(BTRIM(COALESCE(content, '')), '\s+', ' ', 'g')
+WHERE content_html = '';
+
+UPDATE event_journal.journals
+SET excerpt = LEFT(excerpt, 280) || '...'
+WHERE CHAR_LENGTH(excerpt) > 280;
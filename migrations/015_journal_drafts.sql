@@ -0,0 +1,33 @@
+-- Journal drafts and scheduled publishing.
+--
+--   draft      only the author sees it
+--   scheduled  like a draft until publish_at, then the publisher job
+--              flips it to published
+--   published  visible according to its visibility
+--
+-- revision is bumped by every autosave so concurrent editors can detect
+-- they are working on a stale copy.
+
+ALTER TABLE event_journal.journals
+	ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'published'
+		CHECK (status IN ('draft', 'scheduled', 'published')),
+	ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ,
+	ADD COLUMN IF NOT EXISTS published_at TIMESTAMPTZ,
+	ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	ADD COLUMN IF NOT EXISTS revision INT NOT NULL DEFAULT 1;
+
+UPDATE event_journal.journals
+SET published_at = created_at,
+	updated_at = created_at
+WHERE published_at IS NULL AND status = 'published';
+
+-- is_public tetap berarti "tampil di listing publik", draft tidak termasuk
+ALTER TABLE event_journal.journals DROP COLUMN is_public;
+
+ALTER TABLE event_journal.journals
+	ADD COLUMN is_public BOOLEAN
+		GENERATED ALWAYS AS (visibility = 'public' AND status = 'published') STORED;
+
+CREATE INDEX IF NOT EXISTS idx_journals_scheduled
+	ON event_journal.journals (publish_at)
+	WHERE status = 'scheduled';
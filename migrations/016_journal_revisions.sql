@@ -0,0 +1,27 @@
+-- Snapshot of a journal after every change. revision matches
+-- journals.revision at the time the snapshot was taken.
+
+CREATE TABLE IF NOT EXISTS event_journal.journal_revisions (
+	id SERIAL PRIMARY KEY,
+	journal_id INT NOT NULL REFERENCES event_journal.journals(id) ON DELETE CASCADE,
+	revision INT NOT NULL,
+	title TEXT NOT NULL,
+	content TEXT NOT NULL DEFAULT '',
+	content_format TEXT NOT NULL DEFAULT 'plain',
+	latitude DOUBLE PRECISION,
+	longitude DOUBLE PRECISION,
+	visibility TEXT NOT NULL,
+	edited_by INT REFERENCES event_journal.users(id) ON DELETE SET NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	UNIQUE (journal_id, revision)
+);
+
+-- jurnal yang sudah ada mulai dengan satu revisi: isinya sekarang
+INSERT INTO event_journal.journal_revisions (
+	journal_id, revision, title, content, content_format,
+	latitude, longitude, visibility, edited_by, created_at
+)
+SELECT id, revision, title, COALESCE(content, ''), content_format,
+       latitude, longitude, visibility, user_id, updated_at
+FROM event_journal.journals
+ON CONFLICT (journal_id, revision) DO NOTHING;
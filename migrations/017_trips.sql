@@ -0,0 +1,26 @@
+-- Trips group several journals into one ordered, multi-day story. Each
+-- entry keeps its own location; occurred_at is when it happened (the
+-- journal may be written later).
+
+CREATE TABLE IF NOT EXISTS event_journal.trips (
+	id SERIAL PRIMARY KEY,
+	user_id INT NOT NULL REFERENCES event_journal.users(id) ON DELETE CASCADE,
+	event_id INT REFERENCES event_journal.events(id) ON DELETE SET NULL,
+	title TEXT NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	visibility TEXT NOT NULL DEFAULT 'private'
+		CHECK (visibility IN ('private', 'followers', 'unlisted', 'public')),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_trips_user
+	ON event_journal.trips(user_id);
+
+ALTER TABLE event_journal.journals
+	ADD COLUMN IF NOT EXISTS trip_id INT REFERENCES event_journal.trips(id) ON DELETE SET NULL,
+	ADD COLUMN IF NOT EXISTS trip_position INT,
+	ADD COLUMN IF NOT EXISTS occurred_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_journals_trip
+	ON event_journal.journals(trip_id, trip_position);
@@ -0,0 +1,49 @@
+-- Free-form tags on journals and admin-curated categories on events.
+-- Tags are stored once by slug (lowercase, "-" for spaces) so #Jazz and
+-- #jazz are the same tag.
+
+CREATE TABLE IF NOT EXISTS event_journal.tags (
+	id SERIAL PRIMARY KEY,
+	slug TEXT NOT NULL UNIQUE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- autocomplete pakai prefix slug
+CREATE INDEX IF NOT EXISTS idx_tags_slug_prefix
+	ON event_journal.tags (slug text_pattern_ops);
+
+CREATE TABLE IF NOT EXISTS event_journal.journal_tags (
+	journal_id INT NOT NULL REFERENCES event_journal.journals(id) ON DELETE CASCADE,
+	tag_id INT NOT NULL REFERENCES event_journal.tags(id) ON DELETE CASCADE,
+	PRIMARY KEY (journal_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_journal_tags_tag
+	ON event_journal.journal_tags(tag_id);
+
+CREATE TABLE IF NOT EXISTS event_journal.categories (
+	id SERIAL PRIMARY KEY,
+	slug TEXT NOT NULL UNIQUE,
+	name TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS event_journal.event_categories (
+	event_id INT NOT NULL REFERENCES event_journal.events(id) ON DELETE CASCADE,
+	category_id INT NOT NULL REFERENCES event_journal.categories(id) ON DELETE CASCADE,
+	PRIMARY KEY (event_id, category_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_categories_category
+	ON event_journal.event_categories(category_id);
+
+INSERT INTO event_journal.categories (slug, name) VALUES
+	('music', 'Music'),
+	('food', 'Food & Drink'),
+	('sports', 'Sports'),
+	('arts', 'Arts & Culture'),
+	('festival', 'Festival'),
+	('community', 'Community'),
+	('education', 'Education'),
+	('outdoor', 'Outdoor')
+ON CONFLICT (slug) DO NOTHING;
@@ -0,0 +1,29 @@
+-- A journal attached to an event is a verified check-in when it was
+-- written at the event's location while the event was running.
+-- verified_at stays NULL for every other journal.
+
+ALTER TABLE event_journal.journals
+	ADD COLUMN IF NOT EXISTS verified_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_journals_event_verified
+	ON event_journal.journals(event_id)
+	WHERE verified_at IS NOT NULL;
+
+-- jurnal lama dicek pakai occurred_at (atau created_at) dan radius default 1 km
+UPDATE event_journal.journals j SET
+	verified_at = COALESCE(j.occurred_at, j.created_at)
+FROM event_journal.events e
+WHERE e.id = j.event_id
+  AND j.verified_at IS NULL
+  AND j.latitude IS NOT NULL
+  AND j.longitude IS NOT NULL
+  AND e.start_date IS NOT NULL
+  AND e.end_date IS NOT NULL
+  AND COALESCE(j.occurred_at, j.created_at) BETWEEN e.start_date AND e.end_date
+  AND 6371 * acos(LEAST(1,
+	cos(radians(e.latitude)) *
+	cos(radians(j.latitude)) *
+	cos(radians(j.longitude) - radians(e.longitude)) +
+	sin(radians(e.latitude)) *
+	sin(radians(j.latitude))
+  )) <= 1;
@@ -0,0 +1,39 @@
+-- Offline sync: the app creates journals and image records with its own
+-- UUIDs so a retried upload never creates them twice, and downloads what
+-- changed since its last sync token.
+
+ALTER TABLE event_journal.journals
+	ADD COLUMN IF NOT EXISTS client_id UUID;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_journals_user_client_id
+	ON event_journal.journals(user_id, client_id);
+
+CREATE INDEX IF NOT EXISTS idx_journals_user_updated
+	ON event_journal.journals(user_id, updated_at);
+
+-- gambar bisa dicatat dulu saat offline, file-nya menyusul
+ALTER TABLE event_journal.journal_images
+	ADD COLUMN IF NOT EXISTS client_id UUID,
+	ALTER COLUMN image_url DROP NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_journal_images_client_id
+	ON event_journal.journal_images(journal_id, client_id);
+
+ALTER TABLE event_journal.bookmarks
+	ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+UPDATE event_journal.bookmarks SET updated_at = created_at;
+
+-- Unlikes and removed bookmarks, so the next sync can tell the app to
+-- drop them. Purged after SYNC_TOMBSTONE_RETENTION.
+CREATE TABLE IF NOT EXISTS event_journal.sync_tombstones (
+	id BIGSERIAL PRIMARY KEY,
+	user_id INT NOT NULL REFERENCES event_journal.users(id) ON DELETE CASCADE,
+	kind TEXT NOT NULL CHECK (kind IN ('like', 'bookmark')),
+	journal_id INT NOT NULL,
+	deleted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	UNIQUE (user_id, kind, journal_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sync_tombstones_user_deleted
+	ON event_journal.sync_tombstones(user_id, deleted_at);
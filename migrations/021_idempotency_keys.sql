@@ -0,0 +1,17 @@
+-- Idempotency-Key: the first response to a mutating request is kept per
+-- user and key, and replayed when the client retries with the same key.
+-- status_code stays NULL while the first request is still running.
+
+CREATE TABLE IF NOT EXISTS event_journal.idempotency_keys (
+	user_id INT NOT NULL REFERENCES event_journal.users(id) ON DELETE CASCADE,
+	key TEXT NOT NULL,
+	fingerprint TEXT NOT NULL,
+	status_code INT,
+	content_type TEXT,
+	response_body BYTEA,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created
+	ON event_journal.idempotency_keys(created_at);
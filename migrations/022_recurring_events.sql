@@ -0,0 +1,24 @@
+-- Recurring organizer events. start_date/end_date stay the first
+-- occurrence; recurrence_rule (an RRULE subset) repeats it, computed in
+-- the event's timezone so it keeps its local time across DST changes.
+-- Occurrences are expanded on read, only cancellations are stored.
+
+ALTER TABLE event_journal.events
+	ADD COLUMN IF NOT EXISTS recurrence_rule TEXT,
+	ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT 'UTC';
+
+CREATE TABLE IF NOT EXISTS event_journal.event_occurrence_cancellations (
+	event_id INT NOT NULL REFERENCES event_journal.events(id) ON DELETE CASCADE,
+	occurrence_start TIMESTAMPTZ NOT NULL,
+	reason TEXT NOT NULL DEFAULT '',
+	cancelled_by INT REFERENCES event_journal.users(id) ON DELETE SET NULL,
+	cancelled_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (event_id, occurrence_start)
+);
+
+-- jurnal bisa merujuk satu kejadian tertentu dari event berulang
+ALTER TABLE event_journal.journals
+	ADD COLUMN IF NOT EXISTS occurrence_start TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_journals_event_occurrence
+	ON event_journal.journals(event_id, occurrence_start);
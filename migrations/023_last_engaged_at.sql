@@ -0,0 +1,41 @@
+-- Trending windows filter on the last real engagement (like, comment,
+-- bookmark, follow, new journal). Views still add to popularity_score but
+-- don't move last_engaged_at, so one view can't pull an old item back
+-- into "trending this week".
+
+ALTER TABLE event_journal.journals
+	ADD COLUMN IF NOT EXISTS last_engaged_at TIMESTAMPTZ;
+
+ALTER TABLE event_journal.events
+	ADD COLUMN IF NOT EXISTS last_engaged_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_journals_last_engaged
+	ON event_journal.journals(last_engaged_at);
+
+CREATE INDEX IF NOT EXISTS idx_events_last_engaged
+	ON event_journal.events(last_engaged_at);
+
+UPDATE event_journal.journals j SET
+	last_engaged_at = (
+		SELECT MAX(t) FROM (
+			SELECT MAX(l.created_at) AS t FROM event_journal.journal_likes l WHERE l.journal_id = j.id
+			UNION ALL
+			SELECT MAX(c.created_at) FROM event_journal.comments c WHERE c.journal_id = j.id
+			UNION ALL
+			SELECT MAX(b.created_at) FROM event_journal.bookmarks b WHERE b.journal_id = j.id
+		) engaged
+	)
+WHERE j.last_engaged_at IS NULL;
+
+-- event: engagement jurnalnya, follow, atau jurnal baru
+UPDATE event_journal.events e SET
+	last_engaged_at = (
+		SELECT MAX(t) FROM (
+			SELECT MAX(j.last_engaged_at) AS t FROM event_journal.journals j WHERE j.event_id = e.id
+			UNION ALL
+			SELECT MAX(j.created_at) FROM event_journal.journals j WHERE j.event_id = e.id
+			UNION ALL
+			SELECT MAX(f.created_at) FROM event_journal.event_follows f WHERE f.event_id = e.id
+		) engaged
+	)
+WHERE e.last_engaged_at IS NULL;
@@ -16,35 +16,78 @@ func SetupRoutes(r *gin.Engine) {
 
 		// PROTECTED ROUTES
 		api.GET("/me", middleware.JWTAuthMiddleware(), controllers.Me)
-		api.POST("/bookmarks", middleware.JWTAuthMiddleware(), controllers.BookmarkJournal)
-		api.DELETE("/bookmarks/:journal_id", middleware.JWTAuthMiddleware(), controllers.UnbookmarkJournal)
+		api.PUT("/me", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.UpdateProfile)
+		api.POST("/me/avatar", middleware.JWTAuthMiddleware(), controllers.UploadAvatar)
+		api.PUT("/me/locale", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.UpdateLocale)
+		api.PUT("/me/digest", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.UpdateDigestPreference)
+		api.POST("/bookmarks", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.BookmarkJournal)
+		api.DELETE("/bookmarks/:journal_id", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.UnbookmarkJournal)
+		api.PATCH("/bookmarks/:journal_id", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.UpdateBookmark)
 		api.GET("/bookmarks", middleware.JWTAuthMiddleware(), controllers.GetMyBookmarks)
 
+		// BOOKMARK COLLECTIONS
+		api.POST("/collections", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.CreateCollection)
+		api.GET("/collections", middleware.JWTAuthMiddleware(), controllers.GetMyCollections)
+		api.GET("/collections/:id", middleware.OptionalJWT(), controllers.GetCollection)
+		api.PATCH("/collections/:id", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.UpdateCollection)
+		api.DELETE("/collections/:id", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.DeleteCollection)
+
+		// PUSH TARGETING
+		api.POST("/me/fcm-token", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.SaveFCMToken)
+		api.PUT("/me/home-area", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.SaveHomeArea)
+		api.DELETE("/me/home-area", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.ClearHomeArea)
+		api.GET("/me/locations", middleware.JWTAuthMiddleware(), controllers.GetFollowedLocations)
+		api.POST("/me/locations", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.FollowLocation)
+		api.DELETE("/me/locations/:id", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.UnfollowLocation)
+
+		// PUBLIC PROFILES
+		api.GET("/users/:username", middleware.OptionalJWT(), controllers.GetUserProfile)
+		api.GET("/users/:username/followers", controllers.GetFollowers)
+		api.GET("/users/:username/following", controllers.GetFollowing)
+		api.GET("/users/:username/collections", middleware.OptionalJWT(), controllers.GetUserCollections)
+		api.POST("/users/:username/follow", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.FollowUser)
+		api.DELETE("/users/:username/follow", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.UnfollowUser)
+
+		// HOME FEED
+		api.GET("/feed", middleware.JWTAuthMiddleware(), controllers.GetFeed)
+
 		// LEGACY EVENTS (USER / MARKER ONLY)
-		api.POST("/events", middleware.JWTAuthMiddleware(), controllers.CreateEvent)
+		api.POST("/events", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.CreateEvent)
 		api.GET("/events", middleware.JWTAuthMiddleware(), controllers.GetMyEvents)
 
 		// ⬇️ HARUS DI ATAS :id
 		api.GET("/events/all", controllers.GetEvents)
-		api.GET("/events/:id/journals", controllers.GetEventJournals)
+		api.GET("/events/trending", controllers.GetTrendingEvents)
+		api.GET("/events/:id/journals", middleware.OptionalJWT(), controllers.GetEventJournals)
+		api.POST("/events/:id/follow", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.FollowEvent)
+		api.DELETE("/events/:id/follow", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.UnfollowEvent)
 
 		// ⬇️ PALING BAWAH
-		api.GET("/events/:id", controllers.GetEventDetail)
+		api.GET("/events/:id", middleware.OptionalJWT(), controllers.GetEventDetail)
 
-		api.POST("/journals", middleware.JWTAuthMiddleware(), controllers.CreateJournal)
+		api.POST("/journals", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.CreateJournal)
 		api.GET("/journals", middleware.JWTAuthMiddleware(), controllers.GetMyJournals)
-		api.GET("/journals/public", controllers.GetPublicJournals)
+		api.GET("/journals/public", middleware.OptionalJWT(), controllers.GetPublicJournals)
+		api.GET("/journals/trending", middleware.OptionalJWT(), controllers.GetTrendingJournals)
 
 		// MAP ROUTES
-		api.GET("/map/journals", controllers.GetMapJournals)
+		api.GET("/map/journals", middleware.OptionalJWT(), controllers.GetMapJournals)
 
 		// JOURNAL LIKES ROUTES
-		api.POST("/journals/:id/like",
+		api.PUT("/journals/:id/like",
+			middleware.JWTAuthMiddleware(),
+			middleware.Idempotency(),
+			controllers.LikeJournal,
+		)
+
+		api.DELETE("/journals/:id/like",
 			middleware.JWTAuthMiddleware(),
-			controllers.ToggleJournalLike,
+			middleware.Idempotency(),
+			controllers.UnlikeJournal,
 		)
 
 		api.GET("/journals/:id/likes",
+			middleware.OptionalJWT(),
 			controllers.GetJournalLikes,
 		)
 
@@ -54,11 +97,60 @@ func SetupRoutes(r *gin.Engine) {
 			middleware.JWTAuthMiddleware(),
 			controllers.UploadJournalImage,
 		)
+		api.GET("/journals/:id/images/:image_id", middleware.OptionalJWT(), controllers.ServeJournalImage)
+		api.PUT("/journals/:id/visibility", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.UpdateJournalVisibility)
+
+		// DRAFTS & PUBLISHING
+		api.PATCH("/journals/:id", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.UpdateJournal)
+		api.POST("/journals/:id/publish", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.PublishJournal)
+		api.DELETE("/journals/:id/schedule", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.UnscheduleJournal)
+
+		// REVISION HISTORY
+		api.GET("/journals/:id/revisions", middleware.JWTAuthMiddleware(), controllers.GetJournalRevisions)
+		api.GET("/journals/:id/revisions/diff", middleware.JWTAuthMiddleware(), controllers.DiffJournalRevisions)
+		api.GET("/journals/:id/revisions/:revision", middleware.JWTAuthMiddleware(), controllers.GetJournalRevision)
+		api.POST("/journals/:id/revisions/:revision/restore", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.RestoreJournalRevision)
+
+		// SHARE LINKS
+		api.POST("/journals/:id/share-links", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.CreateShareLink)
+		api.GET("/journals/:id/share-links", middleware.JWTAuthMiddleware(), controllers.GetShareLinks)
+		api.DELETE("/journals/:id/share-links/:link_id", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.RevokeShareLink)
+		api.GET("/shared/:token", middleware.OptionalJWT(), controllers.GetSharedJournal)
+		api.GET("/shared/:token/images/:image_id", controllers.ServeSharedJournalImage)
+
+		// TRIPS
+		api.POST("/trips", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.CreateTrip)
+		api.GET("/trips", middleware.JWTAuthMiddleware(), controllers.GetMyTrips)
+		api.GET("/trips/:id", middleware.OptionalJWT(), controllers.GetTrip)
+		api.PATCH("/trips/:id", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.UpdateTrip)
+		api.DELETE("/trips/:id", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.DeleteTrip)
+		api.GET("/trips/:id/route", middleware.OptionalJWT(), controllers.GetTripRoute)
+		api.POST("/trips/:id/entries", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.AddTripEntry)
+		api.PUT("/trips/:id/entries/order", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.ReorderTripEntries)
+		api.DELETE("/trips/:id/entries/:journal_id", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.RemoveTripEntry)
+
+		// OFFLINE SYNC
+		api.POST("/sync", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.Sync)
+
+		// TAGS & CATEGORIES
+		api.GET("/tags", controllers.GetTags)
+		api.GET("/tags/:tag", middleware.OptionalJWT(), controllers.GetTagPage)
+		api.GET("/categories", controllers.GetCategories)
+		api.PUT("/journals/:id/tags", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.UpdateJournalTags)
 
 		// COMMENT ROUTES
-		api.POST("/journals/:id/comments", middleware.JWTAuthMiddleware(), controllers.CreateComment)
-		api.GET("/journals/:id/comments", controllers.GetJournalComments)
-		api.DELETE("/comments/:id", middleware.JWTAuthMiddleware(), controllers.DeleteComment)
+		api.POST("/journals/:id/comments", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.CreateComment)
+		api.GET("/journals/:id/comments", middleware.OptionalJWT(), controllers.GetJournalComments)
+		api.PATCH("/comments/:id", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.UpdateComment)
+		api.GET("/comments/:id/revisions", middleware.OptionalJWT(), controllers.GetCommentRevisions)
+		api.DELETE("/comments/:id", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.DeleteComment)
+
+		// REACTIONS
+		api.GET("/reactions", controllers.GetReactionOptions)
+		api.PUT("/journals/:id/reactions/:reaction", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.AddJournalReaction)
+		api.DELETE("/journals/:id/reactions/:reaction", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.RemoveJournalReaction)
+		api.PUT("/comments/:id/reactions/:reaction", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.AddCommentReaction)
+		api.DELETE("/comments/:id/reactions/:reaction", middleware.JWTAuthMiddleware(), middleware.Idempotency(), controllers.RemoveCommentReaction)
 
 		//BOOKMARK ROUTES
 		api.GET("/journals/:id", middleware.OptionalJWT(), controllers.GetJournalDetail)
@@ -66,6 +158,7 @@ func SetupRoutes(r *gin.Engine) {
 		// ORGANIZER EVENTS (PUBLIC ACTIVITIES)
 		api.POST("/organizer/events",
 			middleware.JWTAuthMiddleware(),
+			middleware.Idempotency(),
 			controllers.CreateOrganizerEvent,
 		)
 
@@ -74,15 +167,34 @@ func SetupRoutes(r *gin.Engine) {
 		)
 
 		api.GET("/organizer/events/:id",
+			middleware.OptionalJWT(),
 			controllers.GetOrganizerEventDetail,
 		)
+
+		// kejadian event berulang: dibatalkan / dipulihkan oleh organizer atau admin
+		api.POST("/organizer/events/:id/cancellations",
+			middleware.JWTAuthMiddleware(),
+			middleware.Idempotency(),
+			controllers.CancelEventOccurrence,
+		)
+
+		api.DELETE("/organizer/events/:id/cancellations",
+			middleware.JWTAuthMiddleware(),
+			middleware.Idempotency(),
+			controllers.RestoreEventOccurrence,
+		)
 		// ADMIN ROUTES
 		admin := api.Group("/admin")
-		admin.Use(middleware.JWTAuthMiddleware(), middleware.AdminOnly())
+		admin.Use(middleware.JWTAuthMiddleware(), middleware.AdminOnly(), middleware.Idempotency())
 		{
 			admin.GET("/events/pending", controllers.GetPendingEvents)
 			admin.PUT("/events/:id/approve", controllers.ApproveEvent)
 			admin.PUT("/events/:id/reject", controllers.RejectEvent)
+			admin.PUT("/events/:id/categories", controllers.SetEventCategories)
+
+			admin.POST("/categories", controllers.CreateCategory)
+			admin.PATCH("/categories/:id", controllers.UpdateCategory)
+			admin.DELETE("/categories/:id", controllers.DeleteCategory)
 		}
 	}
 
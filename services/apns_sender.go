@@ -0,0 +1,183 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	apnsProductionHost = "https://api.push.apple.com"
+	apnsSandboxHost    = "https://api.sandbox.push.apple.com"
+
+	// Apple menolak provider token yang lebih tua dari 1 jam
+	apnsTokenTTL = 50 * time.Minute
+)
+
+// APNsSender talks to the APNs HTTP/2 provider API (or anything that speaks
+// it) using token-based authentication with a .p8 signing key.
+type APNsSender struct {
+	host   string
+	topic  string
+	keyID  string
+	teamID string
+	key    *ecdsa.PrivateKey
+	client *http.Client
+
+	mu       sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+func NewAPNsSenderFromEnv() (*APNsSender, error) {
+	keyFile := os.Getenv("APNS_KEY_FILE")
+	keyID := os.Getenv("APNS_KEY_ID")
+	teamID := os.Getenv("APNS_TEAM_ID")
+	topic := os.Getenv("APNS_TOPIC")
+
+	if keyFile == "" || keyID == "" || teamID == "" || topic == "" {
+		return nil, errors.New("APNS_KEY_FILE, APNS_KEY_ID, APNS_TEAM_ID and APNS_TOPIC are required")
+	}
+
+	pem, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := jwt.ParseECPrivateKeyFromPEM(pem)
+	if err != nil {
+		return nil, err
+	}
+
+	host := apnsSandboxHost
+	if os.Getenv("APNS_PRODUCTION") == "true" {
+		host = apnsProductionHost
+	}
+	if custom := os.Getenv("APNS_HOST"); custom != "" {
+		host = custom
+	}
+
+	return &APNsSender{
+		host:   host,
+		topic:  topic,
+		keyID:  keyID,
+		teamID: teamID,
+		key:    key,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (s *APNsSender) providerToken() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Since(s.issuedAt) < apnsTokenTTL {
+		return s.token, nil
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": s.teamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = s.keyID
+
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		return "", err
+	}
+
+	s.token = signed
+	s.issuedAt = now
+
+	return signed, nil
+}
+
+func apnsPayload(msg PushMessage) ([]byte, error) {
+	payload := map[string]any{
+		"aps": map[string]any{
+			"alert": map[string]string{
+				"title": msg.Title,
+				"body":  msg.Body,
+			},
+			"sound": "default",
+		},
+	}
+
+	for k, v := range msg.Data {
+		if k != "aps" {
+			payload[k] = v
+		}
+	}
+
+	return json.Marshal(payload)
+}
+
+func (s *APNsSender) SendToToken(ctx context.Context, token string, msg PushMessage) error {
+	body, err := apnsPayload(msg)
+	if err != nil {
+		return err
+	}
+
+	bearer, err := s.providerToken()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.host+"/3/device/"+token, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("authorization", "bearer "+bearer)
+	req.Header.Set("apns-topic", s.topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("content-type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apnsErr struct {
+			Reason string `json:"reason"`
+		}
+		raw, _ := io.ReadAll(resp.Body)
+		json.Unmarshal(raw, &apnsErr)
+
+		return fmt.Errorf("apns: status %d: %s", resp.StatusCode, apnsErr.Reason)
+	}
+
+	return nil
+}
+
+func (s *APNsSender) SendToTokens(ctx context.Context, tokens []string, msg PushMessage) (int, error) {
+	failed := 0
+
+	for _, token := range tokens {
+		if err := s.SendToToken(ctx, token, msg); err != nil {
+			if ctx.Err() != nil {
+				return failed + 1, ctx.Err()
+			}
+			failed++
+		}
+	}
+
+	return failed, nil
+}
+
+func (s *APNsSender) SendToTopic(ctx context.Context, topic string, msg PushMessage) error {
+	return ErrTopicUnsupported
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// FileMailer writes every message as an .eml file instead of sending it,
+// so emails can be opened in a mail client during local development.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg EmailMessage) error {
+	now := time.Now()
+
+	raw, err := buildMIMEMessage(m.from, msg, now)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s_%d.eml", now.Format("20060102T150405"), now.UnixNano())
+	path := filepath.Join(m.dir, name)
+
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		return err
+	}
+
+	log.Println("Email captured:", path)
+	return nil
+}
+
+type CapturedEmail struct {
+	Message EmailMessage
+	Raw     []byte
+	SentAt  time.Time
+}
+
+// MemoryMailer keeps messages in memory; useful for tests.
+type MemoryMailer struct {
+	from string
+
+	mu   sync.Mutex
+	sent []CapturedEmail
+}
+
+func NewMemoryMailer(from string) *MemoryMailer {
+	return &MemoryMailer{from: from}
+}
+
+func (m *MemoryMailer) Send(ctx context.Context, msg EmailMessage) error {
+	now := time.Now()
+
+	raw, err := buildMIMEMessage(m.from, msg, now)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.sent = append(m.sent, CapturedEmail{Message: msg, Raw: raw, SentAt: now})
+	m.mu.Unlock()
+
+	return nil
+}
+
+// Sent returns a copy of every captured message.
+func (m *MemoryMailer) Sent() []CapturedEmail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return slices.Clone(m.sent)
+}
+
+func (m *MemoryMailer) Reset() {
+	m.mu.Lock()
+	m.sent = nil
+	m.mu.Unlock()
+}
@@ -0,0 +1,20 @@
+package services
+
+import (
+	"os"
+	"strconv"
+)
+
+const defaultCheckInRadiusKm = 1.0
+
+// CheckInRadiusKm returns how close to the event location a journal must
+// be written to count as a verified check-in. Override with
+// CHECKIN_RADIUS_KM.
+func CheckInRadiusKm() float64 {
+	radius, err := strconv.ParseFloat(os.Getenv("CHECKIN_RADIUS_KM"), 64)
+	if err != nil || radius <= 0 {
+		return defaultCheckInRadiusKm
+	}
+
+	return radius
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+// Format isi jurnal.
+const (
+	ContentFormatPlain    = "plain"
+	ContentFormatMarkdown = "markdown"
+	ContentFormatBlocks   = "blocks"
+
+	excerptLength        = 280
+	defaultPreviewLength = 80
+	imageReferencePrefix = "image:"
+	maxContentBlocks     = 200
+)
+
+var (
+	ErrInvalidContent = errors.New("invalid content document")
+
+	markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+	contentPolicy = func() *bluemonday.Policy {
+		p := bluemonday.UGCPolicy()
+		p.AddTargetBlankToFullyQualifiedLinks(true)
+		return p
+	}()
+
+	whitespace = regexp.MustCompile(`\s+`)
+)
+
+func ValidContentFormat(format string) bool {
+	switch format {
+	case ContentFormatPlain, ContentFormatMarkdown, ContentFormatBlocks:
+		return true
+	}
+	return false
+}
+
+// ContentBlock is one block of a "blocks" journal document:
+//
+//	{"type": "paragraph", "text": "..."}
+//	{"type": "heading", "level": 2, "text": "..."}
+//	{"type": "quote", "text": "..."}
+//	{"type": "list", "ordered": false, "items": ["...", "..."]}
+//	{"type": "image", "image_id": 12, "caption": "..."}
+type ContentBlock struct {
+	Type    string   `json:"type"`
+	Text    string   `json:"text,omitempty"`
+	Level   int      `json:"level,omitempty"`
+	Ordered bool     `json:"ordered,omitempty"`
+	Items   []string `json:"items,omitempty"`
+	ImageID int      `json:"image_id,omitempty"`
+	Caption string   `json:"caption,omitempty"`
+}
+
+type ContentDocument struct {
+	Blocks []ContentBlock `json:"blocks"`
+}
+
+type RenderedContent struct {
+	HTML    string
+	Excerpt string
+}
+
+// RenderContent turns journal source into sanitized HTML and a plain-text
+// excerpt. Images are referenced by their journal_images id, as
+// ![alt](image:12) in Markdown or an "image" block; imageURL maps the id
+// to the URL the reader should load it from.
+func RenderContent(format, source string, imageURL func(imageID int) string) (RenderedContent, error) {
+	var (
+		raw string
+		err error
+	)
+
+	switch format {
+	case ContentFormatPlain:
+		raw = "<p>" + strings.ReplaceAll(html.EscapeString(source), "\n", "<br>") + "</p>"
+	case ContentFormatMarkdown:
+		raw, err = renderMarkdown(source, imageURL)
+	case ContentFormatBlocks:
+		raw, err = renderBlocks(source, imageURL)
+	default:
+		err = ErrInvalidContent
+	}
+	if err != nil {
+		return RenderedContent{}, err
+	}
+
+	rendered := contentPolicy.Sanitize(raw)
+
+	return RenderedContent{
+		HTML:    rendered,
+		Excerpt: TruncateRunes(PlainText(rendered), excerptLength),
+	}, nil
+}
+
+// PlainText strips tags from rendered HTML and collapses whitespace.
+func PlainText(rendered string) string {
+	// tag penutup & <br> dipisah spasi supaya kata antar blok tidak menempel
+	spaced := strings.NewReplacer("</", " </", "<br", " <br").Replace(rendered)
+	stripped := html.UnescapeString(bluemonday.StrictPolicy().Sanitize(spaced))
+
+	return strings.TrimSpace(whitespace.ReplaceAllString(stripped, " "))
+}
+
+// TruncateRunes cuts s to at most n characters without splitting a
+// multi-byte character, adding "..." when something was cut.
+func TruncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:n])) + "..."
+}
+
+// PreviewLength reads the preview size from JOURNAL_PREVIEW_LENGTH
+// (default 80). A requested length overrides it. Previews are cut from the
+// stored excerpt, so the length is capped at the excerpt's 280 characters.
+func PreviewLength(requested string) int {
+	length := defaultPreviewLength
+	if n, err := strconv.Atoi(os.Getenv("JOURNAL_PREVIEW_LENGTH")); err == nil && n > 0 {
+		length = n
+	}
+
+	if n, err := strconv.Atoi(requested); err == nil && n > 0 {
+		length = n
+	}
+
+	return min(length, excerptLength)
+}
+
+func parseImageReference(dest string) (int, bool) {
+	if !strings.HasPrefix(dest, imageReferencePrefix) {
+		return 0, false
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(dest, imageReferencePrefix))
+	return id, err == nil && id > 0
+}
+
+func renderMarkdown(source string, imageURL func(int) string) (string, error) {
+	src := []byte(source)
+	doc := markdown.Parser().Parse(text.NewReader(src))
+
+	err := ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if img, ok := n.(*ast.Image); ok && entering {
+			if id, ok := parseImageReference(string(img.Destination)); ok {
+				img.Destination = []byte(imageURL(id))
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := markdown.Renderer().Render(&buf, src, doc); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func renderBlocks(source string, imageURL func(int) string) (string, error) {
+	var doc ContentDocument
+	if err := json.Unmarshal([]byte(source), &doc); err != nil {
+		return "", ErrInvalidContent
+	}
+
+	if len(doc.Blocks) > maxContentBlocks {
+		return "", ErrInvalidContent
+	}
+
+	var b strings.Builder
+
+	for _, block := range doc.Blocks {
+		switch block.Type {
+		case "paragraph":
+			fmt.Fprintf(&b, "<p>%s</p>", html.EscapeString(block.Text))
+		case "heading":
+			level := min(max(block.Level, 1), 6)
+			fmt.Fprintf(&b, "<h%d>%s</h%d>", level, html.EscapeString(block.Text), level)
+		case "quote":
+			fmt.Fprintf(&b, "<blockquote><p>%s</p></blockquote>", html.EscapeString(block.Text))
+		case "list":
+			tag := "ul"
+			if block.Ordered {
+				tag = "ol"
+			}
+			b.WriteString("<" + tag + ">")
+			for _, item := range block.Items {
+				fmt.Fprintf(&b, "<li>%s</li>", html.EscapeString(item))
+			}
+			b.WriteString("</" + tag + ">")
+		case "image":
+			if block.ImageID <= 0 {
+				return "", ErrInvalidContent
+			}
+			fmt.Fprintf(
+				&b,
+				`<figure><img src="%s" alt="%s"><figcaption>%s</figcaption></figure>`,
+				html.EscapeString(imageURL(block.ImageID)),
+				html.EscapeString(block.Caption),
+				html.EscapeString(block.Caption),
+			)
+		default:
+			return "", ErrInvalidContent
+		}
+	}
+
+	return b.String(), nil
+}
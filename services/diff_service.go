@@ -0,0 +1,87 @@
+package services
+
+import "strings"
+
+// Jenis baris dalam diff.
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+
+	// di atas ini tabel LCS terlalu besar, diff jatuh ke hapus-semua/tambah-semua
+	maxDiffCells = 4_000_000
+)
+
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// DiffLines is a line-based diff from a to b, using the longest common
+// subsequence of lines.
+func DiffLines(a, b string) []DiffLine {
+	from := splitLines(a)
+	to := splitLines(b)
+
+	if len(from)*len(to) > maxDiffCells {
+		return replaceAll(from, to)
+	}
+
+	// lcs[i][j] = panjang LCS dari from[i:] dan to[j:]
+	lcs := make([][]int, len(from)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	diff := make([]DiffLine, 0, len(from)+len(to))
+	i, j := 0, 0
+	for i < len(from) && j < len(to) {
+		switch {
+		case from[i] == to[j]:
+			diff = append(diff, DiffLine{DiffEqual, from[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, DiffLine{DiffDelete, from[i]})
+			i++
+		default:
+			diff = append(diff, DiffLine{DiffInsert, to[j]})
+			j++
+		}
+	}
+	for ; i < len(from); i++ {
+		diff = append(diff, DiffLine{DiffDelete, from[i]})
+	}
+	for ; j < len(to); j++ {
+		diff = append(diff, DiffLine{DiffInsert, to[j]})
+	}
+
+	return diff
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
+
+func replaceAll(from, to []string) []DiffLine {
+	diff := make([]DiffLine, 0, len(from)+len(to))
+	for _, line := range from {
+		diff = append(diff, DiffLine{DiffDelete, line})
+	}
+	for _, line := range to {
+		diff = append(diff, DiffLine{DiffInsert, line})
+	}
+	return diff
+}
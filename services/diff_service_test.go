@@ -0,0 +1,111 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []DiffLine
+	}{
+		{
+			name: "both empty",
+			want: []DiffLine{},
+		},
+		{
+			name: "identical",
+			a:    "one\ntwo",
+			b:    "one\ntwo",
+			want: []DiffLine{{DiffEqual, "one"}, {DiffEqual, "two"}},
+		},
+		{
+			name: "from empty",
+			b:    "one\ntwo",
+			want: []DiffLine{{DiffInsert, "one"}, {DiffInsert, "two"}},
+		},
+		{
+			name: "to empty",
+			a:    "one\ntwo",
+			want: []DiffLine{{DiffDelete, "one"}, {DiffDelete, "two"}},
+		},
+		{
+			name: "line inserted in the middle",
+			a:    "one\nthree",
+			b:    "one\ntwo\nthree",
+			want: []DiffLine{{DiffEqual, "one"}, {DiffInsert, "two"}, {DiffEqual, "three"}},
+		},
+		{
+			name: "line deleted at the end",
+			a:    "one\ntwo\nthree",
+			b:    "one\ntwo",
+			want: []DiffLine{{DiffEqual, "one"}, {DiffEqual, "two"}, {DiffDelete, "three"}},
+		},
+		{
+			// baris yang diubah jadi hapus lalu tambah
+			name: "changed line is a delete then an insert",
+			a:    "one\ntwo\nthree",
+			b:    "one\n2\nthree",
+			want: []DiffLine{{DiffEqual, "one"}, {DiffDelete, "two"}, {DiffInsert, "2"}, {DiffEqual, "three"}},
+		},
+		{
+			name: "CRLF and LF lines compare equal",
+			a:    "one\r\ntwo",
+			b:    "one\ntwo",
+			want: []DiffLine{{DiffEqual, "one"}, {DiffEqual, "two"}},
+		},
+		{
+			name: "empty lines are kept",
+			a:    "one\n\ntwo",
+			b:    "one\ntwo",
+			want: []DiffLine{{DiffEqual, "one"}, {DiffDelete, ""}, {DiffEqual, "two"}},
+		},
+		{
+			name: "longest common subsequence is kept",
+			a:    "a\nb\nc\nd",
+			b:    "b\nc\nx\nd",
+			want: []DiffLine{{DiffDelete, "a"}, {DiffEqual, "b"}, {DiffEqual, "c"}, {DiffInsert, "x"}, {DiffEqual, "d"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffLines(tt.a, tt.b)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("DiffLines(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+// Above maxDiffCells the diff falls back to deleting every old line and
+// inserting every new one.
+func TestDiffLinesLargeInput(t *testing.T) {
+	lines := func(prefix string, n int) string {
+		items := make([]string, n)
+		for i := range items {
+			items[i] = prefix
+		}
+		return strings.Join(items, "\n")
+	}
+
+	a := lines("a", 2001)
+	b := lines("a", 2000)
+
+	got := DiffLines(a, b)
+	if len(got) != 4001 {
+		t.Fatalf("got %d lines, want 4001", len(got))
+	}
+	for i, line := range got {
+		want := DiffDelete
+		if i >= 2001 {
+			want = DiffInsert
+		}
+		if line.Op != want {
+			t.Fatalf("line %d is %q, want %q", i, line.Op, want)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"event-journal-backend/config"
+)
+
+const (
+	digestPeriod = 7 * 24 * time.Hour
+
+	// lokasi jurnal yang dianggap "recent" untuk mencari event terdekat
+	digestRecentJournalWindow = 30 * 24 * time.Hour
+
+	digestTrendingLimit = 5
+)
+
+type DigestJournalActivity struct {
+	ID          int
+	Title       string
+	NewLikes    int
+	NewComments int
+	URL         string
+}
+
+type DigestEvent struct {
+	ID           int
+	Title        string
+	LocationName string
+	URL          string
+}
+
+type DigestJournal struct {
+	ID     int
+	Title  string
+	Author string
+	Likes  int
+	URL    string
+}
+
+type DigestData struct {
+	Name             string
+	TotalNewLikes    int
+	TotalNewComments int
+	Activity         []DigestJournalActivity
+	NearbyEvents     []DigestEvent
+	Trending         []DigestJournal
+	SettingsURL      string
+}
+
+func (d DigestData) IsEmpty() bool {
+	return len(d.Activity) == 0 && len(d.NearbyEvents) == 0 && len(d.Trending) == 0
+}
+
+type digestRecipient struct {
+	ID     int
+	Name   string
+	Email  string
+	Locale string
+	Since  time.Time
+}
+
+// StartDigestScheduler checks hourly (DIGEST_CHECK_INTERVAL) for opted-in
+// users whose last digest is at least a week old.
+func StartDigestScheduler() {
+	interval := time.Hour
+	if d, err := time.ParseDuration(os.Getenv("DIGEST_CHECK_INTERVAL")); err == nil && d > 0 {
+		interval = d
+	}
+
+	StartJob("weekly-digest", interval, SendDueDigests)
+}
+
+func SendDueDigests(ctx context.Context) error {
+	query := `
+	SELECT id, name, email, COALESCE(locale, ''),
+	       COALESCE(last_digest_sent_at, $1)
+	FROM event_journal.users
+	WHERE digest_opt_in = true
+	  AND (last_digest_sent_at IS NULL OR last_digest_sent_at <= $1)
+	`
+
+	rows, err := config.DB.Query(ctx, query, time.Now().Add(-digestPeriod))
+	if err != nil {
+		return err
+	}
+
+	var recipients []digestRecipient
+	for rows.Next() {
+		var r digestRecipient
+		if err := rows.Scan(&r.ID, &r.Name, &r.Email, &r.Locale, &r.Since); err != nil {
+			rows.Close()
+			return err
+		}
+		recipients = append(recipients, r)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, r := range recipients {
+		if err := sendDigest(ctx, r); err != nil {
+			log.Printf("Digest for user %d failed: %v\n", r.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func sendDigest(ctx context.Context, r digestRecipient) error {
+	data, err := BuildDigest(ctx, r.ID, r.Since)
+	if err != nil {
+		return err
+	}
+	data.Name = r.Name
+
+	// minggu yang sepi tidak perlu email, tapi tetap dihitung sudah dikirim
+	if !data.IsEmpty() {
+		if err := SendTemplateEmail(r.Email, r.Locale, "digest", data); err != nil {
+			return err
+		}
+	}
+
+	_, err = config.DB.Exec(
+		ctx,
+		`UPDATE event_journal.users SET last_digest_sent_at = NOW() WHERE id = $1`,
+		r.ID,
+	)
+
+	return err
+}
+
+// BuildDigest collects everything that happened for userID since the given
+// time: engagement on their journals, approved events near where they have
+// been journaling lately, and the week's trending public journals.
+func BuildDigest(ctx context.Context, userID int, since time.Time) (DigestData, error) {
+	frontend := os.Getenv("FRONTEND_URL")
+	data := DigestData{SettingsURL: frontend + "/settings/notifications"}
+
+	activityQuery := `
+	SELECT j.id, j.title,
+	       (SELECT COUNT(*) FROM event_journal.journal_likes l
+	        WHERE l.journal_id = j.id AND l.user_id <> $1 AND l.created_at > $2),
+	       (SELECT COUNT(*) FROM event_journal.comments c
+	        WHERE c.journal_id = j.id AND c.user_id <> $1 AND c.created_at > $2
+	          AND c.deleted_at IS NULL)
+	FROM event_journal.journals j
+	WHERE j.user_id = $1
+	`
+
+	rows, err := config.DB.Query(ctx, activityQuery, userID, since)
+	if err != nil {
+		return data, err
+	}
+
+	for rows.Next() {
+		var a DigestJournalActivity
+		if err := rows.Scan(&a.ID, &a.Title, &a.NewLikes, &a.NewComments); err != nil {
+			rows.Close()
+			return data, err
+		}
+		if a.NewLikes == 0 && a.NewComments == 0 {
+			continue
+		}
+
+		a.URL = frontend + "/journals/" + strconv.Itoa(a.ID)
+		data.TotalNewLikes += a.NewLikes
+		data.TotalNewComments += a.NewComments
+		data.Activity = append(data.Activity, a)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return data, err
+	}
+
+	eventsQuery := `
+	SELECT DISTINCT e.id, e.title, COALESCE(e.location_name, '')
+	FROM event_journal.events e
+	JOIN event_journal.event_moderation_logs m
+	  ON m.event_id = e.id AND m.action = 'approved' AND m.created_at > $2
+	JOIN event_journal.journals j
+	  ON j.user_id = $1
+	 AND j.created_at > $4
+	 AND j.latitude IS NOT NULL
+	 AND j.longitude IS NOT NULL
+	WHERE e.status = 'approved'
+	  AND ` + DistanceKmSQL("j.latitude", "j.longitude", "e.latitude", "e.longitude") + ` <= $3
+	`
+
+	rows, err = config.DB.Query(
+		ctx,
+		eventsQuery,
+		userID,
+		since,
+		NewEventPushRadiusKm(),
+		time.Now().Add(-digestRecentJournalWindow),
+	)
+	if err != nil {
+		return data, err
+	}
+
+	for rows.Next() {
+		var e DigestEvent
+		if err := rows.Scan(&e.ID, &e.Title, &e.LocationName); err != nil {
+			rows.Close()
+			return data, err
+		}
+
+		e.URL = eventURL(e.ID)
+		data.NearbyEvents = append(data.NearbyEvents, e)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return data, err
+	}
+
+	trendingQuery := `
+	SELECT j.id, j.title, u.name, j.like_count
+	FROM event_journal.journals j
+	JOIN event_journal.users u ON u.id = j.user_id
+	WHERE j.is_public = true
+	  AND j.user_id <> $1
+	  AND j.created_at > $2
+	ORDER BY j.like_count + j.comment_count DESC, j.created_at DESC
+	LIMIT $3
+	`
+
+	rows, err = config.DB.Query(ctx, trendingQuery, userID, time.Now().Add(-digestPeriod), digestTrendingLimit)
+	if err != nil {
+		return data, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var j DigestJournal
+		if err := rows.Scan(&j.ID, &j.Title, &j.Author, &j.Likes); err != nil {
+			return data, err
+		}
+
+		j.URL = frontend + "/journals/" + strconv.Itoa(j.ID)
+		data.Trending = append(data.Trending, j)
+	}
+
+	return data, rows.Err()
+}
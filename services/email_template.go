@@ -2,17 +2,150 @@ package services
 
 import (
 	"bytes"
-	"html/template"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+
+	"event-journal-backend/i18n"
+	"event-journal-backend/templates"
+)
+
+// emailCatalog holds the translated strings used by the email templates
+// and their subjects (templates/emails/locales).
+var emailCatalog = i18n.MustLoadCatalog(templates.Emails, "emails/locales")
+
+// Setiap halaman email (approved.html, approved.txt, ...) di-parse sekali
+// di atas layout.html / layout.txt dan mengisi block "content".
+var (
+	htmlEmailTemplates = mustParseHTMLEmails()
+	textEmailTemplates = mustParseTextEmails()
 )
 
-func RenderEmailTemplate(name string, data any) (string, error) {
-	tmpl, err := template.ParseFiles("templates/emails/" + name)
+// EmailSubject returns the localized subject for an email page.
+func EmailSubject(name, locale string) string {
+	return emailCatalog.T(locale, name+".subject")
+}
+
+// htmlEmailFuncs escapes translation arguments unless they are already
+// template.HTML (e.g. produced by strong), since catalogs are trusted.
+func htmlEmailFuncs(locale string) htmltemplate.FuncMap {
+	return htmltemplate.FuncMap{
+		"t": func(key string, args ...any) htmltemplate.HTML {
+			escaped := make([]any, len(args))
+			for i, arg := range args {
+				if html, ok := arg.(htmltemplate.HTML); ok {
+					escaped[i] = html
+					continue
+				}
+				escaped[i] = htmltemplate.HTMLEscaper(arg)
+			}
+
+			return htmltemplate.HTML(emailCatalog.T(locale, key, escaped...))
+		},
+		"strong": func(s string) htmltemplate.HTML {
+			return htmltemplate.HTML("<strong>" + htmltemplate.HTMLEscapeString(s) + "</strong>")
+		},
+	}
+}
+
+func textEmailFuncs(locale string) texttemplate.FuncMap {
+	return texttemplate.FuncMap{
+		"t": func(key string, args ...any) string {
+			return emailCatalog.T(locale, key, args...)
+		},
+	}
+}
+
+func emailPages(ext string) []string {
+	files, err := fs.Glob(templates.Emails, "emails/*"+ext)
+	if err != nil {
+		panic(err)
+	}
+
+	var pages []string
+	for _, file := range files {
+		if path.Base(file) != "layout"+ext {
+			pages = append(pages, file)
+		}
+	}
+
+	return pages
+}
+
+func mustParseHTMLEmails() map[string]*htmltemplate.Template {
+	layout := htmltemplate.Must(
+		htmltemplate.New("layout.html").
+			Funcs(htmlEmailFuncs(i18n.Default)).
+			ParseFS(templates.Emails, "emails/layout.html"),
+	)
+
+	parsed := map[string]*htmltemplate.Template{}
+	for _, page := range emailPages(".html") {
+		t := htmltemplate.Must(htmltemplate.Must(layout.Clone()).ParseFS(templates.Emails, page))
+		parsed[strings.TrimSuffix(path.Base(page), ".html")] = t
+	}
+
+	return parsed
+}
+
+func mustParseTextEmails() map[string]*texttemplate.Template {
+	layout := texttemplate.Must(
+		texttemplate.New("layout.txt").
+			Funcs(textEmailFuncs(i18n.Default)).
+			ParseFS(templates.Emails, "emails/layout.txt"),
+	)
+
+	parsed := map[string]*texttemplate.Template{}
+	for _, page := range emailPages(".txt") {
+		t := texttemplate.Must(texttemplate.Must(layout.Clone()).ParseFS(templates.Emails, page))
+		parsed[strings.TrimSuffix(path.Base(page), ".txt")] = t
+	}
+
+	return parsed
+}
+
+// RenderEmailTemplate renders the HTML version of an email page, e.g.
+// "approved.html" or just "approved", in the given locale.
+func RenderEmailTemplate(name, locale string, data any) (string, error) {
+	name = strings.TrimSuffix(name, ".html")
+
+	parsed, ok := htmlEmailTemplates[name]
+	if !ok {
+		return "", fs.ErrNotExist
+	}
+
+	// clone supaya func "t" bisa diganti per locale tanpa race
+	tmpl, err := parsed.Clone()
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Funcs(htmlEmailFuncs(locale)).ExecuteTemplate(&buf, "layout.html", data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// RenderTextEmailTemplate renders the plain-text part of an email page.
+func RenderTextEmailTemplate(name, locale string, data any) (string, error) {
+	name = strings.TrimSuffix(name, ".txt")
+
+	parsed, ok := textEmailTemplates[name]
+	if !ok {
+		return "", fs.ErrNotExist
+	}
+
+	tmpl, err := parsed.Clone()
 	if err != nil {
 		return "", err
 	}
 
 	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
+	if err := tmpl.Funcs(textEmailFuncs(locale)).ExecuteTemplate(&buf, "layout.txt", data); err != nil {
 		return "", err
 	}
 
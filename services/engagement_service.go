@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"event-journal-backend/config"
+)
+
+// StartEngagementReconciler recounts journal counters every
+// ENGAGEMENT_RECONCILE_INTERVAL (default 6h) to repair any drift.
+func StartEngagementReconciler() {
+	interval := 6 * time.Hour
+	if d, err := time.ParseDuration(os.Getenv("ENGAGEMENT_RECONCILE_INTERVAL")); err == nil && d > 0 {
+		interval = d
+	}
+
+	StartJob("engagement-counters", interval, ReconcileEngagementCounters)
+}
+
+// ReconcileEngagementCounters rewrites like_count, comment_count and
+// bookmark_count from the source tables, touching only rows that drifted.
+func ReconcileEngagementCounters(ctx context.Context) error {
+	query := `
+	UPDATE event_journal.journals j SET
+		like_count = t.likes,
+		comment_count = t.comments,
+		bookmark_count = t.bookmarks
+	FROM (
+		SELECT j2.id,
+		       (SELECT COUNT(*) FROM event_journal.journal_likes l WHERE l.journal_id = j2.id) AS likes,
+		       (SELECT COUNT(*) FROM event_journal.comments c WHERE c.journal_id = j2.id AND c.deleted_at IS NULL) AS comments,
+		       (SELECT COUNT(*) FROM event_journal.bookmarks b WHERE b.journal_id = j2.id) AS bookmarks
+		FROM event_journal.journals j2
+	) t
+	WHERE j.id = t.id
+	  AND (j.like_count, j.comment_count, j.bookmark_count) IS DISTINCT FROM (t.likes, t.comments, t.bookmarks)
+	`
+
+	result, err := config.DB.Exec(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	if n := result.RowsAffected(); n > 0 {
+		log.Printf("Engagement counters repaired on %d journals\n", n)
+	}
+
+	return nil
+}
@@ -2,32 +2,88 @@ package services
 
 import (
 	"context"
-	"log"
+	"os"
 
 	firebase "firebase.google.com/go/v4"
 	"firebase.google.com/go/v4/messaging"
 	"google.golang.org/api/option"
 )
 
-var MessagingClient *messaging.Client
+// FCM accepts at most 500 tokens per multicast request.
+const maxMulticastTokens = 500
 
-func InitFirebase() {
+func firebaseCredentialsFile() string {
+	if path := os.Getenv("FIREBASE_CREDENTIALS_FILE"); path != "" {
+		return path
+	}
 
-	ctx := context.Background()
+	return "serviceAccountKey.json" // <-- TARUH FILE DI ROOT PROJECT
+}
 
-	opt := option.WithCredentialsFile("serviceAccountKey.json") // <-- TARUH FILE DI ROOT PROJECT
+type FCMSender struct {
+	client *messaging.Client
+}
+
+func NewFCMSender(ctx context.Context, credentialsFile string) (*FCMSender, error) {
+	opt := option.WithCredentialsFile(credentialsFile)
 
 	app, err := firebase.NewApp(ctx, nil, opt)
 	if err != nil {
-		log.Fatal("Firebase init error:", err)
+		return nil, err
 	}
 
 	client, err := app.Messaging(ctx)
 	if err != nil {
-		log.Fatal("Firebase messaging error:", err)
+		return nil, err
+	}
+
+	return &FCMSender{client: client}, nil
+}
+
+func fcmNotification(msg PushMessage) *messaging.Notification {
+	return &messaging.Notification{
+		Title: msg.Title,
+		Body:  msg.Body,
 	}
+}
+
+func (s *FCMSender) SendToToken(ctx context.Context, token string, msg PushMessage) error {
+	_, err := s.client.Send(ctx, &messaging.Message{
+		Token:        token,
+		Notification: fcmNotification(msg),
+		Data:         msg.Data,
+	})
+
+	return err
+}
+
+func (s *FCMSender) SendToTokens(ctx context.Context, tokens []string, msg PushMessage) (int, error) {
+	failed := 0
+
+	for start := 0; start < len(tokens); start += maxMulticastTokens {
+		end := min(start+maxMulticastTokens, len(tokens))
+
+		resp, err := s.client.SendEachForMulticast(ctx, &messaging.MulticastMessage{
+			Tokens:       tokens[start:end],
+			Notification: fcmNotification(msg),
+			Data:         msg.Data,
+		})
+		if err != nil {
+			return failed + len(tokens) - start, err
+		}
+
+		failed += resp.FailureCount
+	}
+
+	return failed, nil
+}
 
-	MessagingClient = client
+func (s *FCMSender) SendToTopic(ctx context.Context, topic string, msg PushMessage) error {
+	_, err := s.client.Send(ctx, &messaging.Message{
+		Topic:        topic,
+		Notification: fcmNotification(msg),
+		Data:         msg.Data,
+	})
 
-	log.Println("🔥 Firebase initialized")
+	return err
 }
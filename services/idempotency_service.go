@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"event-journal-backend/config"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	defaultIdempotencyKeyRetention = 24 * time.Hour
+
+	// idempotencyLockTimeout is how long a key stays claimed by a request
+	// that never finished (e.g. the server restarted) before a retry may
+	// take it over.
+	idempotencyLockTimeout = time.Minute
+)
+
+// IdempotencyKeyRetention is how long responses are kept for replay.
+// Override with IDEMPOTENCY_KEY_RETENTION (e.g. "48h").
+func IdempotencyKeyRetention() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("IDEMPOTENCY_KEY_RETENTION")); err == nil && d > 0 {
+		return d
+	}
+	return defaultIdempotencyKeyRetention
+}
+
+// IdempotencyRecord is what is stored for a key. Completed is false while
+// the first request is still running.
+type IdempotencyRecord struct {
+	Fingerprint string
+	Completed   bool
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+// ClaimIdempotencyKey claims key for userID and the request fingerprint.
+// It returns true when the caller should run the request, and otherwise
+// the record of the request that claimed the key first. Expired keys are
+// reused.
+func ClaimIdempotencyKey(ctx context.Context, userID int, key, fingerprint string) (bool, IdempotencyRecord, error) {
+	var record IdempotencyRecord
+
+	query := `
+	INSERT INTO event_journal.idempotency_keys (user_id, key, fingerprint)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (user_id, key) DO UPDATE SET
+		fingerprint = EXCLUDED.fingerprint,
+		status_code = NULL,
+		content_type = NULL,
+		response_body = NULL,
+		created_at = NOW()
+	WHERE idempotency_keys.created_at < NOW() - make_interval(secs => $4)
+	   OR (idempotency_keys.status_code IS NULL
+	       AND idempotency_keys.created_at < NOW() - make_interval(secs => $5))
+	RETURNING user_id
+	`
+
+	var claimedBy int
+	err := config.DB.QueryRow(
+		ctx,
+		query,
+		userID,
+		key,
+		fingerprint,
+		IdempotencyKeyRetention().Seconds(),
+		idempotencyLockTimeout.Seconds(),
+	).Scan(&claimedBy)
+	if err == nil {
+		return true, record, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return false, record, err
+	}
+
+	var statusCode *int
+	var contentType *string
+
+	err = config.DB.QueryRow(
+		ctx,
+		`
+		SELECT fingerprint, status_code, content_type, response_body
+		FROM event_journal.idempotency_keys
+		WHERE user_id = $1 AND key = $2
+		`,
+		userID,
+		key,
+	).Scan(&record.Fingerprint, &statusCode, &contentType, &record.Body)
+	if err != nil {
+		return false, record, err
+	}
+
+	if statusCode != nil {
+		record.Completed = true
+		record.StatusCode = *statusCode
+	}
+	if contentType != nil {
+		record.ContentType = *contentType
+	}
+
+	return false, record, nil
+}
+
+// CompleteIdempotencyKey stores the response of the request that claimed
+// the key.
+func CompleteIdempotencyKey(ctx context.Context, userID int, key, fingerprint string, statusCode int, contentType string, body []byte) error {
+	_, err := config.DB.Exec(
+		ctx,
+		`
+		UPDATE event_journal.idempotency_keys SET
+			status_code = $4,
+			content_type = $5,
+			response_body = $6
+		WHERE user_id = $1 AND key = $2 AND fingerprint = $3 AND status_code IS NULL
+		`,
+		userID,
+		key,
+		fingerprint,
+		statusCode,
+		contentType,
+		body,
+	)
+	return err
+}
+
+// ReleaseIdempotencyKey gives up an unfinished claim so the client can
+// retry, e.g. after a server error.
+func ReleaseIdempotencyKey(ctx context.Context, userID int, key, fingerprint string) error {
+	_, err := config.DB.Exec(
+		ctx,
+		`
+		DELETE FROM event_journal.idempotency_keys
+		WHERE user_id = $1 AND key = $2 AND fingerprint = $3 AND status_code IS NULL
+		`,
+		userID,
+		key,
+		fingerprint,
+	)
+	return err
+}
+
+// StartIdempotencyKeyCleaner purges expired idempotency keys every hour.
+func StartIdempotencyKeyCleaner() {
+	StartJob("idempotency-key-cleaner", time.Hour, PurgeIdempotencyKeys)
+}
+
+func PurgeIdempotencyKeys(ctx context.Context) error {
+	result, err := config.DB.Exec(
+		ctx,
+		`DELETE FROM event_journal.idempotency_keys WHERE created_at < NOW() - make_interval(secs => $1)`,
+		IdempotencyKeyRetention().Seconds(),
+	)
+	if err != nil {
+		return err
+	}
+
+	if n := result.RowsAffected(); n > 0 {
+		log.Printf("🧹 Purged %d idempotency keys\n", n)
+	}
+	return nil
+}
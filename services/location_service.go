@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+
+	"event-journal-backend/config"
+	"event-journal-backend/i18n"
+)
+
+const defaultNewEventPushRadiusKm = 25.0
+
+// NewEventPushRadiusKm returns the radius used to pick which users get a
+// push when an event is approved. Override with NEW_EVENT_PUSH_RADIUS_KM.
+func NewEventPushRadiusKm() float64 {
+	radius, err := strconv.ParseFloat(os.Getenv("NEW_EVENT_PUSH_RADIUS_KM"), 64)
+	if err != nil || radius <= 0 {
+		return defaultNewEventPushRadiusKm
+	}
+
+	return radius
+}
+
+// DistanceKmSQL returns an expression for the great-circle distance in km
+// between two points given as SQL expressions, e.g. columns or "$1". The
+// cosine is clamped to [-1, 1]: for points that coincide rounding can push
+// it just past 1, and acos would then fail the whole query.
+func DistanceKmSQL(lat1, lng1, lat2, lng2 string) string {
+	return "(6371 * acos(GREATEST(-1, LEAST(1, " +
+		"cos(radians(" + lat1 + ")) * cos(radians(" + lat2 + ")) * cos(radians(" + lng2 + ") - radians(" + lng1 + ")) + " +
+		"sin(radians(" + lat1 + ")) * sin(radians(" + lat2 + "))))))"
+}
+
+// GetTokensNearLocation returns the FCM tokens, grouped by the user's
+// locale, of users whose home area or one of their followed locations lies
+// within radiusKm of the given point.
+func GetTokensNearLocation(ctx context.Context, lat, lng, radiusKm float64, excludeUserID int) (map[string][]string, error) {
+	query := `
+	SELECT DISTINCT u.fcm_token, COALESCE(u.locale, '')
+	FROM event_journal.users u
+	LEFT JOIN event_journal.user_followed_locations l ON l.user_id = u.id
+	WHERE u.fcm_token IS NOT NULL
+	  AND u.fcm_token <> ''
+	  AND u.id <> $4
+	  AND (
+	    (
+	      u.home_latitude IS NOT NULL
+	      AND u.home_longitude IS NOT NULL
+	      AND ` + DistanceKmSQL("$1", "$2", "u.home_latitude", "u.home_longitude") + ` <= $3
+	    )
+	    OR (
+	      l.id IS NOT NULL
+	      AND ` + DistanceKmSQL("$1", "$2", "l.latitude", "l.longitude") + ` <= $3
+	    )
+	  )
+	`
+
+	rows, err := config.DB.Query(ctx, query, lat, lng, radiusKm, excludeUserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := map[string][]string{}
+
+	for rows.Next() {
+		var token, locale string
+		if err := rows.Scan(&token, &locale); err != nil {
+			return nil, err
+		}
+		tokens[locale] = append(tokens[locale], token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// NotifyUsersNearEvent pushes a "new event" notification to every user
+// around the event location instead of the global all-users topic.
+func NotifyUsersNearEvent(eventID int) error {
+	ctx := context.Background()
+
+	var (
+		title     string
+		lat, lng  float64
+		createdBy int
+	)
+
+	query := `
+	SELECT title, latitude, longitude, created_by
+	FROM event_journal.events
+	WHERE id = $1
+	`
+
+	err := config.DB.QueryRow(ctx, query, eventID).
+		Scan(&title, &lat, &lng, &createdBy)
+	if err != nil {
+		log.Println("Nearby push: event lookup failed:", err)
+		return err
+	}
+
+	tokens, err := GetTokensNearLocation(ctx, lat, lng, NewEventPushRadiusKm(), createdBy)
+	if err != nil {
+		log.Println("Nearby push: token lookup failed:", err)
+		return err
+	}
+
+	for locale, localeTokens := range tokens {
+		err := SendPushToTokens(
+			localeTokens,
+			i18n.T(locale, "push.new_event_nearby.title"),
+			title,
+			map[string]string{
+				"type":     "new_event",
+				"event_id": strconv.Itoa(eventID),
+			},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/mail"
+	"os"
+	"strings"
+)
+
+// defaultFromAddress is the sender when neither FROM_EMAIL nor SMTP_USER
+// gives an address, so the file and memory mailers work out of the box.
+const defaultFromAddress = "noreply@localhost"
+
+type EmailMessage struct {
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Mailer delivers an email. MAIL_PROVIDER picks the backend: smtp, file
+// (writes .eml files to MAIL_CAPTURE_DIR) or memory.
+type Mailer interface {
+	Send(ctx context.Context, msg EmailMessage) error
+}
+
+var EmailSender Mailer
+
+func InitMailer() {
+	provider := os.Getenv("MAIL_PROVIDER")
+
+	// tanpa config: kirim via SMTP kalau host ada, kalau tidak simpan ke file
+	if provider == "" {
+		provider = "file"
+		if os.Getenv("SMTP_HOST") != "" {
+			provider = "smtp"
+		}
+	}
+
+	// alamat pengirim dicek sekali di sini, bukan di setiap kiriman
+	from, err := mailFromAddress()
+	if err != nil {
+		log.Fatal("Mailer init error:", err)
+	}
+
+	var mailer Mailer
+
+	switch provider {
+	case "smtp":
+		mailer, err = NewSMTPMailerFromEnv(from)
+	case "file":
+		dir := os.Getenv("MAIL_CAPTURE_DIR")
+		if dir == "" {
+			dir = "tmp/mail"
+		}
+		mailer, err = NewFileMailer(dir, from)
+	case "memory":
+		mailer = NewMemoryMailer(from)
+	default:
+		err = errors.New("unknown MAIL_PROVIDER " + provider)
+	}
+
+	if err != nil {
+		log.Fatal("Mailer init error:", err)
+	}
+
+	EmailSender = mailer
+
+	log.Println("📧 Mail provider:", provider)
+}
+
+// mailFromAddress is the From of every email. FROM_EMAIL is the From
+// header as before: a full address ("Event Journal <noreply@example.com>"),
+// a bare address, or only a display name ("Event Journal"), which is then
+// put in front of SMTP_USER when that is an address, else
+// defaultFromAddress.
+func mailFromAddress() (string, error) {
+	from := strings.TrimSpace(os.Getenv("FROM_EMAIL"))
+
+	if strings.Contains(from, "@") {
+		addr, err := mail.ParseAddress(from)
+		if err != nil {
+			return "", errors.New("invalid FROM_EMAIL: " + err.Error())
+		}
+		return addr.String(), nil
+	}
+
+	addr, err := mail.ParseAddress(os.Getenv("SMTP_USER"))
+	if err != nil {
+		addr = &mail.Address{Address: defaultFromAddress}
+	}
+	addr.Name = from
+
+	return addr.String(), nil
+}
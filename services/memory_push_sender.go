@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"log"
+	"slices"
+	"sync"
+	"time"
+)
+
+type RecordedPush struct {
+	Tokens  []string
+	Topic   string
+	Message PushMessage
+	SentAt  time.Time
+}
+
+// MemoryPushSender records every push instead of delivering it. It is used
+// when no push provider is configured and by tests of the push flows.
+type MemoryPushSender struct {
+	mu   sync.Mutex
+	sent []RecordedPush
+}
+
+func NewMemoryPushSender() *MemoryPushSender {
+	return &MemoryPushSender{}
+}
+
+func (s *MemoryPushSender) record(push RecordedPush) {
+	push.SentAt = time.Now()
+
+	s.mu.Lock()
+	s.sent = append(s.sent, push)
+	s.mu.Unlock()
+
+	log.Printf("Push (memory): %q to %d token(s) topic=%q\n", push.Message.Title, len(push.Tokens), push.Topic)
+}
+
+func (s *MemoryPushSender) SendToToken(ctx context.Context, token string, msg PushMessage) error {
+	s.record(RecordedPush{Tokens: []string{token}, Message: msg})
+	return nil
+}
+
+func (s *MemoryPushSender) SendToTokens(ctx context.Context, tokens []string, msg PushMessage) (int, error) {
+	s.record(RecordedPush{Tokens: slices.Clone(tokens), Message: msg})
+	return 0, nil
+}
+
+func (s *MemoryPushSender) SendToTopic(ctx context.Context, topic string, msg PushMessage) error {
+	s.record(RecordedPush{Topic: topic, Message: msg})
+	return nil
+}
+
+// Sent returns a copy of everything recorded so far.
+func (s *MemoryPushSender) Sent() []RecordedPush {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.sent)
+}
+
+func (s *MemoryPushSender) Reset() {
+	s.mu.Lock()
+	s.sent = nil
+	s.mu.Unlock()
+}
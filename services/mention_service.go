@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"event-journal-backend/config"
+	"event-journal-backend/i18n"
+)
+
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9_.]{3,30})`)
+
+// ExtractMentions returns the distinct lowercased usernames mentioned as
+// @username in text.
+func ExtractMentions(text string) []string {
+	seen := map[string]bool{}
+	var usernames []string
+
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		// titik di akhir kalimat bukan bagian dari username
+		username := strings.ToLower(strings.TrimRight(match[1], "."))
+		if len(username) < 3 || seen[username] {
+			continue
+		}
+		seen[username] = true
+		usernames = append(usernames, username)
+	}
+
+	return usernames
+}
+
+// NotifyCommentMentions notifies users mentioned in a comment who can see
+// the journal. Users already notified for this comment are skipped, so it
+// is safe to call again after an edit.
+func NotifyCommentMentions(commentID, journalID, authorID int, usernames []string) {
+	if len(usernames) == 0 {
+		return
+	}
+
+	ctx := context.Background()
+
+	var author string
+	err := config.DB.QueryRow(
+		ctx,
+		`SELECT COALESCE(NULLIF(display_name, ''), username) FROM event_journal.users WHERE id = $1`,
+		authorID,
+	).Scan(&author)
+	if err != nil {
+		log.Println("Mention author lookup failed:", err)
+		return
+	}
+
+	query := `
+	WITH inserted AS (
+		INSERT INTO event_journal.comment_mentions (comment_id, user_id)
+		SELECT $1, u.id
+		FROM event_journal.users u
+		JOIN event_journal.journals j ON j.id = $2
+		WHERE LOWER(u.username) = ANY($3)
+		  AND u.id <> $4
+		  AND (
+		    u.id = j.user_id
+		    OR (j.status = 'published' AND (
+		      j.visibility IN ('public', 'unlisted')
+		      OR (j.visibility = 'followers' AND EXISTS (
+		        SELECT 1 FROM event_journal.user_follows f
+		        WHERE f.follower_id = u.id AND f.followee_id = j.user_id
+		      ))
+		    ))
+		  )
+		ON CONFLICT DO NOTHING
+		RETURNING user_id
+	)
+	SELECT u.id, COALESCE(u.locale, ''), COALESCE(u.fcm_token, '')
+	FROM inserted i
+	JOIN event_journal.users u ON u.id = i.user_id
+	`
+
+	rows, err := config.DB.Query(ctx, query, commentID, journalID, usernames, authorID)
+	if err != nil {
+		log.Println("Mention lookup failed:", err)
+		return
+	}
+	defer rows.Close()
+
+	data := map[string]string{
+		"type":       "comment_mention",
+		"journal_id": strconv.Itoa(journalID),
+		"comment_id": strconv.Itoa(commentID),
+	}
+
+	for rows.Next() {
+		var userID int
+		var locale, token string
+
+		if err := rows.Scan(&userID, &locale, &token); err != nil {
+			log.Println("Mention scan failed:", err)
+			return
+		}
+
+		title := i18n.T(locale, "push.comment_mention.title")
+		body := i18n.T(locale, "push.comment_mention.body", author)
+
+		if token != "" {
+			go SendPushToToken(token, title, body, data)
+		}
+		go SaveNotification(userID, title, body)
+	}
+}
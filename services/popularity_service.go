@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"event-journal-backend/config"
+)
+
+// Bobot tiap interaksi terhadap skor popularitas.
+const (
+	PopularityView     = 1.0
+	PopularityLike     = 3.0
+	PopularityBookmark = 4.0
+	PopularityComment  = 5.0
+	PopularityFollow   = 4.0
+	PopularityJournal  = 5.0
+
+	// sebagian engagement jurnal ikut menaikkan event-nya
+	eventShareOfJournalPopularity = 0.5
+
+	defaultPopularityHalfLife = 72 * time.Hour
+)
+
+// PopularityDecayRate is the per-second decay constant derived from
+// POPULARITY_HALF_LIFE (default 72h).
+func PopularityDecayRate() float64 {
+	halfLife := defaultPopularityHalfLife
+	if d, err := time.ParseDuration(os.Getenv("POPULARITY_HALF_LIFE")); err == nil && d > 0 {
+		halfLife = d
+	}
+
+	return math.Ln2 / halfLife.Seconds()
+}
+
+// DecayedScoreSQL returns an expression for the current score of a row
+// with popularity_score/popularity_updated_at columns under alias. The
+// decay rate goes in as a placeholder, e.g. "$3".
+func DecayedScoreSQL(alias, rateParam string) string {
+	return "(" + alias + ".popularity_score * EXP(-" + rateParam +
+		"::float8 * EXTRACT(EPOCH FROM (NOW() - " + alias + ".popularity_updated_at))))"
+}
+
+// engagedWeight reports whether an interaction of weight counts as
+// engagement for the trending window: anything heavier than a view, and
+// never an undo.
+func engagedWeight(weight float64) bool {
+	return weight > PopularityView
+}
+
+// BumpJournalPopularity decays the stored score to now and adds weight
+// (negative to undo an interaction). The journal's event gets a share.
+func BumpJournalPopularity(journalID int, weight float64) {
+	query := `
+	WITH j AS (
+		UPDATE event_journal.journals SET
+			popularity_score = GREATEST(0,
+				popularity_score * EXP(-$2::float8 * EXTRACT(EPOCH FROM (NOW() - popularity_updated_at)))
+				+ $3),
+			popularity_updated_at = NOW(),
+			last_engaged_at = CASE WHEN $5 THEN NOW() ELSE last_engaged_at END
+		WHERE id = $1
+		RETURNING event_id
+	)
+	UPDATE event_journal.events e SET
+		popularity_score = GREATEST(0,
+			e.popularity_score * EXP(-$2::float8 * EXTRACT(EPOCH FROM (NOW() - e.popularity_updated_at)))
+			+ $3 * $4),
+		popularity_updated_at = NOW(),
+		last_engaged_at = CASE WHEN $5 THEN NOW() ELSE e.last_engaged_at END
+	FROM j
+	WHERE e.id = j.event_id
+	`
+
+	_, err := config.DB.Exec(
+		context.Background(),
+		query,
+		journalID,
+		PopularityDecayRate(),
+		weight,
+		eventShareOfJournalPopularity,
+		engagedWeight(weight),
+	)
+	if err != nil {
+		log.Println("Journal popularity bump failed:", err)
+	}
+}
+
+func BumpEventPopularity(eventID int, weight float64) {
+	query := `
+	UPDATE event_journal.events SET
+		popularity_score = GREATEST(0,
+			popularity_score * EXP(-$2::float8 * EXTRACT(EPOCH FROM (NOW() - popularity_updated_at)))
+			+ $3),
+		popularity_updated_at = NOW(),
+		last_engaged_at = CASE WHEN $4 THEN NOW() ELSE last_engaged_at END
+	WHERE id = $1
+	`
+
+	_, err := config.DB.Exec(context.Background(), query, eventID, PopularityDecayRate(), weight, engagedWeight(weight))
+	if err != nil {
+		log.Println("Event popularity bump failed:", err)
+	}
+}
+
+// RecordJournalView counts a view and bumps popularity.
+func RecordJournalView(journalID int) {
+	_, err := config.DB.Exec(
+		context.Background(),
+		`UPDATE event_journal.journals SET view_count = view_count + 1 WHERE id = $1`,
+		journalID,
+	)
+	if err != nil {
+		log.Println("Journal view count failed:", err)
+		return
+	}
+
+	BumpJournalPopularity(journalID, PopularityView)
+}
+
+// ParseTrendingWindow accepts "24h", "7d", "30d" or any Go duration.
+func ParseTrendingWindow(window string) (time.Duration, bool) {
+	switch window {
+	case "", "7d":
+		return 7 * 24 * time.Hour, true
+	case "24h", "1d":
+		return 24 * time.Hour, true
+	case "30d":
+		return 30 * 24 * time.Hour, true
+	}
+
+	if days, ok := strings.CutSuffix(window, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err == nil && n > 0 && n <= 365 {
+			return time.Duration(n) * 24 * time.Hour, true
+		}
+		return 0, false
+	}
+
+	d, err := time.ParseDuration(window)
+	return d, err == nil && d > 0
+}
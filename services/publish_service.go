@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"event-journal-backend/config"
+	"event-journal-backend/i18n"
+)
+
+// StartJournalPublisher publishes scheduled journals whose publish_at has
+// passed, checking every JOURNAL_PUBLISH_INTERVAL (default 1m).
+func StartJournalPublisher() {
+	interval := time.Minute
+	if d, err := time.ParseDuration(os.Getenv("JOURNAL_PUBLISH_INTERVAL")); err == nil && d > 0 {
+		interval = d
+	}
+
+	StartJob("journal-publisher", interval, PublishDueJournals)
+}
+
+// PublishDueJournals flips due scheduled journals to published and lets
+// the authors' followers know.
+func PublishDueJournals(ctx context.Context) error {
+	query := `
+	UPDATE event_journal.journals SET
+		status = 'published',
+		published_at = NOW(),
+		publish_at = NULL,
+		updated_at = NOW()
+	WHERE status = 'scheduled'
+	  AND publish_at <= NOW()
+	RETURNING id, event_id
+	`
+
+	rows, err := config.DB.Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	published := 0
+	for rows.Next() {
+		var journalID int
+		var eventID *int
+
+		if err := rows.Scan(&journalID, &eventID); err != nil {
+			return err
+		}
+
+		if eventID != nil {
+			go BumpEventPopularity(*eventID, PopularityJournal)
+		}
+		go NotifyJournalPublished(journalID)
+		published++
+	}
+
+	if published > 0 {
+		log.Printf("📰 Published %d scheduled journals\n", published)
+	}
+
+	return rows.Err()
+}
+
+// NotifyJournalPublished pushes a newly published journal to the author's
+// followers. Private and unlisted journals are not announced.
+func NotifyJournalPublished(journalID int) {
+	ctx := context.Background()
+
+	var author, title, visibility string
+	var authorID int
+
+	err := config.DB.QueryRow(
+		ctx,
+		`
+		SELECT u.id, COALESCE(NULLIF(u.display_name, ''), u.username), j.title, j.visibility
+		FROM event_journal.journals j
+		JOIN event_journal.users u ON u.id = j.user_id
+		WHERE j.id = $1 AND j.status = 'published'
+		`,
+		journalID,
+	).Scan(&authorID, &author, &title, &visibility)
+
+	if err != nil || (visibility != "public" && visibility != "followers") {
+		return
+	}
+
+	query := `
+	SELECT u.id, COALESCE(u.locale, ''), COALESCE(u.fcm_token, '')
+	FROM event_journal.user_follows f
+	JOIN event_journal.users u ON u.id = f.follower_id
+	WHERE f.followee_id = $1
+	`
+
+	rows, err := config.DB.Query(ctx, query, authorID)
+	if err != nil {
+		log.Println("Follower lookup failed:", err)
+		return
+	}
+	defer rows.Close()
+
+	data := map[string]string{
+		"type":       "journal_published",
+		"journal_id": strconv.Itoa(journalID),
+	}
+
+	for rows.Next() {
+		var userID int
+		var locale, token string
+
+		if err := rows.Scan(&userID, &locale, &token); err != nil {
+			log.Println("Follower scan failed:", err)
+			return
+		}
+
+		pushTitle := i18n.T(locale, "push.journal_published.title", author)
+		body := i18n.T(locale, "push.journal_published.body", title)
+
+		if token != "" {
+			go SendPushToToken(token, pushTitle, body, data)
+		}
+		go SaveNotification(userID, pushTitle, body)
+	}
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+)
+
+type PushMessage struct {
+	Title string
+	Body  string
+	Data  map[string]string
+}
+
+// PushSender delivers notifications to devices. Implementations are picked
+// by PUSH_PROVIDER (fcm, apns or memory) in InitPush.
+type PushSender interface {
+	SendToToken(ctx context.Context, token string, msg PushMessage) error
+	// SendToTokens returns how many tokens could not be delivered to.
+	SendToTokens(ctx context.Context, tokens []string, msg PushMessage) (int, error)
+	SendToTopic(ctx context.Context, topic string, msg PushMessage) error
+}
+
+var ErrTopicUnsupported = errors.New("push provider does not support topics")
+
+var Pusher PushSender
+
+func InitPush() {
+	provider := os.Getenv("PUSH_PROVIDER")
+
+	// tanpa config: pakai FCM kalau credential ada, kalau tidak pakai fake
+	if provider == "" {
+		provider = "memory"
+		if _, err := os.Stat(firebaseCredentialsFile()); err == nil {
+			provider = "fcm"
+		}
+	}
+
+	var (
+		sender PushSender
+		err    error
+	)
+
+	switch provider {
+	case "fcm":
+		sender, err = NewFCMSender(context.Background(), firebaseCredentialsFile())
+	case "apns":
+		sender, err = NewAPNsSenderFromEnv()
+	case "memory":
+		sender = NewMemoryPushSender()
+	default:
+		err = errors.New("unknown PUSH_PROVIDER " + provider)
+	}
+
+	if err != nil {
+		log.Fatal("Push init error:", err)
+	}
+
+	Pusher = sender
+
+	log.Println("🔔 Push provider:", provider)
+}
@@ -3,22 +3,15 @@ package services
 import (
 	"context"
 	"log"
-
-	"firebase.google.com/go/v4/messaging"
 )
 
 func SendPushToToken(token, title, body string, data map[string]string) error {
 
-	message := &messaging.Message{
-		Token: token,
-		Notification: &messaging.Notification{
-			Title: title,
-			Body:  body,
-		},
-		Data: data,
-	}
-
-	_, err := MessagingClient.Send(context.Background(), message)
+	err := Pusher.SendToToken(context.Background(), token, PushMessage{
+		Title: title,
+		Body:  body,
+		Data:  data,
+	})
 	if err != nil {
 		log.Println("Push failed:", err)
 		return err
@@ -29,20 +22,34 @@ func SendPushToToken(token, title, body string, data map[string]string) error {
 
 func BroadcastToAllUsers(title, body string, data map[string]string) error {
 
-	message := &messaging.Message{
-		Topic: "all-users",
-		Notification: &messaging.Notification{
-			Title: title,
-			Body:  body,
-		},
-		Data: data,
+	err := Pusher.SendToTopic(context.Background(), "all-users", PushMessage{
+		Title: title,
+		Body:  body,
+		Data:  data,
+	})
+	if err != nil {
+		log.Println("Broadcast failed:", err)
+		return err
 	}
 
-	_, err := MessagingClient.Send(context.Background(), message)
+	return nil
+}
+
+func SendPushToTokens(tokens []string, title, body string, data map[string]string) error {
+
+	failed, err := Pusher.SendToTokens(context.Background(), tokens, PushMessage{
+		Title: title,
+		Body:  body,
+		Data:  data,
+	})
 	if err != nil {
-		log.Println("Broadcast failed:", err)
+		log.Println("Multicast push failed:", err)
 		return err
 	}
 
+	if failed > 0 {
+		log.Printf("Multicast push: %d of %d failed\n", failed, len(tokens))
+	}
+
 	return nil
 }
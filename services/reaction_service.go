@@ -0,0 +1,30 @@
+package services
+
+import (
+	"os"
+	"slices"
+	"strings"
+)
+
+var defaultReactions = []string{"👍", "❤️", "😂", "😮", "😢", "🎉"}
+
+// AllowedReactions is the reaction set from REACTIONS (comma separated),
+// falling back to a small default set.
+func AllowedReactions() []string {
+	var reactions []string
+	for _, r := range strings.Split(os.Getenv("REACTIONS"), ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			reactions = append(reactions, r)
+		}
+	}
+
+	if len(reactions) == 0 {
+		return defaultReactions
+	}
+
+	return reactions
+}
+
+func IsAllowedReaction(reaction string) bool {
+	return slices.Contains(AllowedReactions(), reaction)
+}
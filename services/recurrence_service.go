@@ -0,0 +1,395 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported RRULE frequencies.
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+	FreqYearly  = "YEARLY"
+)
+
+const (
+	maxRecurrenceCount    = 1000
+	maxRecurrenceInterval = 1000
+
+	// maxRecurrencePeriods bounds how many days/weeks/months/years are
+	// walked from the first occurrence, so a rule that rarely matches
+	// can't loop for long.
+	maxRecurrencePeriods = 10000
+)
+
+var ErrInvalidRecurrence = errors.New("invalid recurrence rule")
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// weekdayRule is one BYDAY entry: every Weekday (N = 0), the Nth of the
+// month, or the Nth from the end when N is negative ("-1FR").
+type weekdayRule struct {
+	Weekday time.Weekday
+	N       int
+}
+
+// Recurrence is a parsed RRULE. The supported subset is FREQ (DAILY,
+// WEEKLY, MONTHLY, YEARLY), INTERVAL, COUNT, UNTIL, BYDAY (ordinals only
+// with MONTHLY) and BYMONTHDAY (MONTHLY only). Weeks start on Monday.
+type Recurrence struct {
+	Freq       string
+	Interval   int
+	Count      int
+	ByDay      []weekdayRule
+	ByMonthDay []int
+
+	until     time.Time
+	untilDate bool // UNTIL tanpa jam: sampai akhir hari itu di zona event
+	hasUntil  bool
+}
+
+// ParseRecurrence parses an RRULE such as "FREQ=WEEKLY;BYDAY=SA;COUNT=10"
+// (an "RRULE:" prefix is allowed).
+func ParseRecurrence(rule string) (Recurrence, error) {
+	r := Recurrence{Interval: 1}
+
+	rule = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(rule)), "RRULE:")
+	if rule == "" {
+		return r, ErrInvalidRecurrence
+	}
+
+	seen := map[string]bool{}
+
+	for _, part := range strings.Split(rule, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" || seen[name] {
+			return r, ErrInvalidRecurrence
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			switch value {
+			case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+				r.Freq = value
+			default:
+				err = ErrInvalidRecurrence
+			}
+		case "INTERVAL":
+			r.Interval, err = boundedInt(value, 1, maxRecurrenceInterval)
+		case "COUNT":
+			r.Count, err = boundedInt(value, 1, maxRecurrenceCount)
+		case "UNTIL":
+			err = r.parseUntil(value)
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseByMonthDay(value)
+		default:
+			err = ErrInvalidRecurrence
+		}
+		if err != nil {
+			return r, ErrInvalidRecurrence
+		}
+	}
+
+	switch {
+	case r.Freq == "":
+		return r, ErrInvalidRecurrence
+	case r.Count > 0 && r.hasUntil:
+		return r, ErrInvalidRecurrence
+	case len(r.ByMonthDay) > 0 && r.Freq != FreqMonthly:
+		return r, ErrInvalidRecurrence
+	case len(r.ByDay) > 0 && r.Freq == FreqYearly:
+		return r, ErrInvalidRecurrence
+	}
+
+	for _, d := range r.ByDay {
+		if d.N != 0 && r.Freq != FreqMonthly {
+			return r, ErrInvalidRecurrence
+		}
+	}
+
+	return r, nil
+}
+
+func boundedInt(value string, min, max int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		return 0, ErrInvalidRecurrence
+	}
+	return n, nil
+}
+
+func (r *Recurrence) parseUntil(value string) error {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		r.until, r.hasUntil = t, true
+		return nil
+	}
+	if t, err := time.Parse("20060102", value); err == nil {
+		r.until, r.untilDate, r.hasUntil = t, true, true
+		return nil
+	}
+	return ErrInvalidRecurrence
+}
+
+func parseByDay(value string) ([]weekdayRule, error) {
+	var days []weekdayRule
+
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, ErrInvalidRecurrence
+		}
+
+		weekday, ok := rruleWeekdays[item[len(item)-2:]]
+		if !ok {
+			return nil, ErrInvalidRecurrence
+		}
+
+		n := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			var err error
+			n, err = strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, ErrInvalidRecurrence
+			}
+		}
+
+		days = append(days, weekdayRule{Weekday: weekday, N: n})
+	}
+
+	return days, nil
+}
+
+func parseByMonthDay(value string) ([]int, error) {
+	var days []int
+
+	for _, item := range strings.Split(value, ",") {
+		d, err := strconv.Atoi(item)
+		if err != nil || d == 0 || d < -31 || d > 31 {
+			return nil, ErrInvalidRecurrence
+		}
+		days = append(days, d)
+	}
+
+	return days, nil
+}
+
+// Occurrence is one run of a recurring event.
+type Occurrence struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Occurrences lists the occurrences of r that overlap [from, to), at most
+// limit of them (0 = no limit). The series starts at dtstart and every
+// occurrence lasts duration. Dates are worked out on the wall clock in
+// loc, so a 09:00 market stays at 09:00 across DST changes.
+func (r Recurrence) Occurrences(dtstart time.Time, duration time.Duration, loc *time.Location, from, to time.Time, limit int) []Occurrence {
+	var found []Occurrence
+
+	r.each(dtstart.In(loc), func(start time.Time) bool {
+		if !start.Before(to) {
+			return false
+		}
+		if start.Add(duration).After(from) {
+			found = append(found, Occurrence{Start: start, End: start.Add(duration)})
+		}
+		return limit == 0 || len(found) < limit
+	})
+
+	return found
+}
+
+// StartsWith reports whether dtstart itself is an occurrence of r, i.e.
+// whether the series really begins at dtstart.
+func (r Recurrence) StartsWith(dtstart time.Time, loc *time.Location) bool {
+	first := false
+	r.each(dtstart.In(loc), func(start time.Time) bool {
+		first = start.Equal(dtstart)
+		return false
+	})
+	return first
+}
+
+// Includes reports whether start is one of the occurrences of r.
+func (r Recurrence) Includes(dtstart time.Time, loc *time.Location, start time.Time) bool {
+	found := false
+	r.each(dtstart.In(loc), func(t time.Time) bool {
+		found = t.Equal(start)
+		return t.Before(start)
+	})
+	return found
+}
+
+// each calls fn with every occurrence start from dtstart on, in order,
+// until fn returns false or the series ends (COUNT, UNTIL or
+// maxRecurrencePeriods).
+func (r Recurrence) each(dtstart time.Time, fn func(time.Time) bool) {
+	loc := dtstart.Location()
+	hour, minute, second := dtstart.Clock()
+
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hour, minute, second, dtstart.Nanosecond(), loc)
+	}
+
+	var until time.Time
+	if r.hasUntil {
+		until = r.until
+		if r.untilDate {
+			until = time.Date(until.Year(), until.Month(), until.Day(), 23, 59, 59, 0, loc)
+		}
+	}
+
+	count := 0
+
+	for period := 0; period < maxRecurrencePeriods; period++ {
+		step := period * r.Interval
+		var candidates []time.Time
+
+		switch r.Freq {
+		case FreqDaily:
+			day := at(dtstart.Year(), dtstart.Month(), dtstart.Day()+step)
+			if r.matchesWeekday(day.Weekday()) {
+				candidates = append(candidates, day)
+			}
+
+		case FreqWeekly:
+			monday := dtstart.Day() - mondayOffset(dtstart.Weekday()) + 7*step
+			for _, weekday := range r.weeklyDays(dtstart.Weekday()) {
+				candidates = append(candidates, at(dtstart.Year(), dtstart.Month(), monday+mondayOffset(weekday)))
+			}
+
+		case FreqMonthly:
+			month := time.Date(dtstart.Year(), dtstart.Month()+time.Month(step), 1, 0, 0, 0, 0, loc)
+			for _, day := range r.monthDays(month.Year(), month.Month(), dtstart.Day()) {
+				candidates = append(candidates, at(month.Year(), month.Month(), day))
+			}
+
+		case FreqYearly:
+			year := dtstart.Year() + step
+			// 29 Feb hanya di tahun kabisat
+			if dtstart.Day() <= daysIn(year, dtstart.Month()) {
+				candidates = append(candidates, at(year, dtstart.Month(), dtstart.Day()))
+			}
+		}
+
+		for _, start := range candidates {
+			if start.Before(dtstart) {
+				continue
+			}
+			if r.hasUntil && start.After(until) {
+				return
+			}
+
+			count++
+			if r.Count > 0 && count > r.Count {
+				return
+			}
+
+			if !fn(start) {
+				return
+			}
+		}
+	}
+}
+
+func (r Recurrence) matchesWeekday(weekday time.Weekday) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, d := range r.ByDay {
+		if d.Weekday == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// weeklyDays are the weekdays of a WEEKLY rule, Monday first.
+func (r Recurrence) weeklyDays(start time.Weekday) []time.Weekday {
+	if len(r.ByDay) == 0 {
+		return []time.Weekday{start}
+	}
+
+	var days []time.Weekday
+	for _, d := range r.ByDay {
+		days = append(days, d.Weekday)
+	}
+	sort.Slice(days, func(i, j int) bool { return mondayOffset(days[i]) < mondayOffset(days[j]) })
+
+	return days
+}
+
+// monthDays are the days of month a MONTHLY rule falls on, in order.
+func (r Recurrence) monthDays(year int, month time.Month, startDay int) []int {
+	last := daysIn(year, month)
+	picked := map[int]bool{}
+
+	switch {
+	case len(r.ByMonthDay) > 0:
+		for _, d := range r.ByMonthDay {
+			if d < 0 {
+				d = last + d + 1
+			}
+			weekday := time.Date(year, month, d, 0, 0, 0, 0, time.UTC).Weekday()
+			if d >= 1 && d <= last && r.matchesWeekday(weekday) {
+				picked[d] = true
+			}
+		}
+
+	case len(r.ByDay) > 0:
+		for _, rule := range r.ByDay {
+			var matches []int
+			for d := 1; d <= last; d++ {
+				if time.Date(year, month, d, 0, 0, 0, 0, time.UTC).Weekday() == rule.Weekday {
+					matches = append(matches, d)
+				}
+			}
+
+			switch {
+			case rule.N == 0:
+				for _, d := range matches {
+					picked[d] = true
+				}
+			case rule.N > 0 && rule.N <= len(matches):
+				picked[matches[rule.N-1]] = true
+			case rule.N < 0 && -rule.N <= len(matches):
+				picked[matches[len(matches)+rule.N]] = true
+			}
+		}
+
+	case startDay <= last:
+		picked[startDay] = true
+	}
+
+	days := make([]int, 0, len(picked))
+	for d := range picked {
+		days = append(days, d)
+	}
+	sort.Ints(days)
+
+	return days
+}
+
+// mondayOffset is how many days weekday comes after Monday.
+func mondayOffset(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
@@ -0,0 +1,341 @@
+package services
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func mustLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("load %s: %v", name, err)
+	}
+	return loc
+}
+
+// wallClock parses "2006-01-02 15:04" in loc.
+func wallClock(t *testing.T, loc *time.Location, value string) time.Time {
+	t.Helper()
+
+	at, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+	if err != nil {
+		t.Fatalf("parse %q: %v", value, err)
+	}
+	return at
+}
+
+func mustRecurrence(t *testing.T, rule string) Recurrence {
+	t.Helper()
+
+	r, err := ParseRecurrence(rule)
+	if err != nil {
+		t.Fatalf("ParseRecurrence(%q): %v", rule, err)
+	}
+	return r
+}
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		rule  string
+		valid bool
+	}{
+		{"FREQ=DAILY", true},
+		{"RRULE:FREQ=WEEKLY;BYDAY=SA;COUNT=10", true},
+		{"freq=monthly;byday=-1fr", true},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1", true},
+		{"FREQ=DAILY;UNTIL=20260105", true},
+		{"FREQ=DAILY;UNTIL=20260105T120000Z", true},
+
+		{"", false},
+		{"COUNT=3", false},
+		{"FREQ=HOURLY", false},
+		{"FREQ=DAILY;FREQ=WEEKLY", false},
+		{"FREQ=DAILY;COUNT=3;UNTIL=20260105", false},
+		{"FREQ=DAILY;INTERVAL=0", false},
+		{"FREQ=DAILY;COUNT=1001", false},
+		{"FREQ=DAILY;UNTIL=2026-01-05", false},
+		{"FREQ=WEEKLY;BYDAY=1MO", false},
+		{"FREQ=MONTHLY;BYDAY=6MO", false},
+		{"FREQ=MONTHLY;BYDAY=0MO", false},
+		{"FREQ=MONTHLY;BYDAY=XX", false},
+		{"FREQ=WEEKLY;BYMONTHDAY=1", false},
+		{"FREQ=MONTHLY;BYMONTHDAY=32", false},
+		{"FREQ=YEARLY;BYDAY=MO", false},
+		{"FREQ=DAILY;BYSETPOS=1", false},
+		{"FREQ=DAILY;COUNT", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			_, err := ParseRecurrence(tt.rule)
+			if (err == nil) != tt.valid {
+				t.Fatalf("ParseRecurrence(%q) error = %v, want valid = %v", tt.rule, err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestRecurrenceOccurrences(t *testing.T) {
+	amsterdam := mustLocation(t, "Europe/Amsterdam")
+	jakarta := mustLocation(t, "Asia/Jakarta")
+
+	tests := []struct {
+		name     string
+		rule     string
+		loc      *time.Location
+		dtstart  string
+		duration time.Duration // kosong = satu jam
+		from     string        // kosong = dtstart
+		to       string
+		limit    int
+		want     []string
+	}{
+		{
+			// DST mulai 29 Maret 2026: tetap jam 09:00 waktu lokal
+			name:    "daily keeps the wall clock across DST start",
+			rule:    "FREQ=DAILY;COUNT=3",
+			loc:     amsterdam,
+			dtstart: "2026-03-28 09:00",
+			to:      "2026-04-30 00:00",
+			want:    []string{"2026-03-28 09:00", "2026-03-29 09:00", "2026-03-30 09:00"},
+		},
+		{
+			name:    "weekly keeps the wall clock across DST end",
+			rule:    "FREQ=WEEKLY;COUNT=2",
+			loc:     amsterdam,
+			dtstart: "2026-10-20 19:30",
+			to:      "2026-12-31 00:00",
+			want:    []string{"2026-10-20 19:30", "2026-10-27 19:30"},
+		},
+		{
+			name:    "last friday of the month",
+			rule:    "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3",
+			loc:     time.UTC,
+			dtstart: "2026-01-30 18:00",
+			to:      "2027-01-01 00:00",
+			want:    []string{"2026-01-30 18:00", "2026-02-27 18:00", "2026-03-27 18:00"},
+		},
+		{
+			name:    "second tuesday of the month",
+			rule:    "FREQ=MONTHLY;BYDAY=2TU;COUNT=3",
+			loc:     time.UTC,
+			dtstart: "2026-01-13 10:00",
+			to:      "2027-01-01 00:00",
+			want:    []string{"2026-01-13 10:00", "2026-02-10 10:00", "2026-03-10 10:00"},
+		},
+		{
+			name:    "fifth sunday only in months that have one",
+			rule:    "FREQ=MONTHLY;BYDAY=5SU;COUNT=3",
+			loc:     time.UTC,
+			dtstart: "2026-03-29 08:00",
+			to:      "2027-01-01 00:00",
+			want:    []string{"2026-03-29 08:00", "2026-05-31 08:00", "2026-08-30 08:00"},
+		},
+		{
+			name:    "last day of the month",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=4",
+			loc:     time.UTC,
+			dtstart: "2026-01-31 12:00",
+			to:      "2027-01-01 00:00",
+			want:    []string{"2026-01-31 12:00", "2026-02-28 12:00", "2026-03-31 12:00", "2026-04-30 12:00"},
+		},
+		{
+			name:    "last day of february in a leap year",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=2",
+			loc:     time.UTC,
+			dtstart: "2028-01-31 12:00",
+			to:      "2029-01-01 00:00",
+			want:    []string{"2028-01-31 12:00", "2028-02-29 12:00"},
+		},
+		{
+			name:    "monthly on the 31st skips shorter months",
+			rule:    "FREQ=MONTHLY;COUNT=3",
+			loc:     time.UTC,
+			dtstart: "2026-01-31 12:00",
+			to:      "2027-01-01 00:00",
+			want:    []string{"2026-01-31 12:00", "2026-03-31 12:00", "2026-05-31 12:00"},
+		},
+		{
+			name:    "yearly on 29 february only in leap years",
+			rule:    "FREQ=YEARLY;COUNT=2",
+			loc:     time.UTC,
+			dtstart: "2024-02-29 12:00",
+			to:      "2040-01-01 00:00",
+			want:    []string{"2024-02-29 12:00", "2028-02-29 12:00"},
+		},
+		{
+			// UNTIL tanpa jam berlaku sampai akhir hari itu di zona event
+			name:    "bare UNTIL date includes that whole day in the event zone",
+			rule:    "FREQ=DAILY;UNTIL=20260105",
+			loc:     jakarta,
+			dtstart: "2026-01-01 20:00",
+			to:      "2027-01-01 00:00",
+			want:    []string{"2026-01-01 20:00", "2026-01-02 20:00", "2026-01-03 20:00", "2026-01-04 20:00", "2026-01-05 20:00"},
+		},
+		{
+			// 5 Jan 20:00 WIB = 13:00Z, sudah lewat UNTIL
+			name:    "UNTIL with a time is a UTC instant",
+			rule:    "FREQ=DAILY;UNTIL=20260105T120000Z",
+			loc:     jakarta,
+			dtstart: "2026-01-01 20:00",
+			to:      "2027-01-01 00:00",
+			want:    []string{"2026-01-01 20:00", "2026-01-02 20:00", "2026-01-03 20:00", "2026-01-04 20:00"},
+		},
+		{
+			name:    "COUNT counts from dtstart, not from the window",
+			rule:    "FREQ=DAILY;COUNT=5",
+			loc:     time.UTC,
+			dtstart: "2026-01-01 09:00",
+			from:    "2026-01-04 00:00",
+			to:      "2027-01-01 00:00",
+			want:    []string{"2026-01-04 09:00", "2026-01-05 09:00"},
+		},
+		{
+			name:    "COUNT skips BYDAY days before dtstart",
+			rule:    "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=3",
+			loc:     time.UTC,
+			dtstart: "2026-01-07 10:00",
+			to:      "2027-01-01 00:00",
+			want:    []string{"2026-01-07 10:00", "2026-01-12 10:00", "2026-01-14 10:00"},
+		},
+		{
+			name:    "weekly BYDAY with an interval",
+			rule:    "FREQ=WEEKLY;INTERVAL=2;BYDAY=SU,SA",
+			loc:     time.UTC,
+			dtstart: "2026-01-03 07:00",
+			to:      "2026-01-19 00:00",
+			want:    []string{"2026-01-03 07:00", "2026-01-04 07:00", "2026-01-17 07:00", "2026-01-18 07:00"},
+		},
+		{
+			name:    "daily BYDAY filters weekdays",
+			rule:    "FREQ=DAILY;BYDAY=SA,SU",
+			loc:     time.UTC,
+			dtstart: "2026-01-03 07:00",
+			to:      "2026-01-12 00:00",
+			want:    []string{"2026-01-03 07:00", "2026-01-04 07:00", "2026-01-10 07:00", "2026-01-11 07:00"},
+		},
+		{
+			name:     "a run still in progress at from is included",
+			rule:     "FREQ=DAILY",
+			loc:      time.UTC,
+			dtstart:  "2026-01-01 09:00",
+			duration: 2 * time.Hour,
+			from:     "2026-01-02 10:00",
+			to:       "2026-01-03 12:00",
+			want:     []string{"2026-01-02 09:00", "2026-01-03 09:00"},
+		},
+		{
+			name:    "limit",
+			rule:    "FREQ=DAILY",
+			loc:     time.UTC,
+			dtstart: "2026-01-01 09:00",
+			to:      "2027-01-01 00:00",
+			limit:   2,
+			want:    []string{"2026-01-01 09:00", "2026-01-02 09:00"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := mustRecurrence(t, tt.rule)
+
+			dtstart := wallClock(t, tt.loc, tt.dtstart)
+			from := dtstart
+			if tt.from != "" {
+				from = wallClock(t, tt.loc, tt.from)
+			}
+			to := wallClock(t, tt.loc, tt.to)
+
+			duration := tt.duration
+			if duration == 0 {
+				duration = time.Hour
+			}
+
+			got := r.Occurrences(dtstart, duration, tt.loc, from, to, tt.limit)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d occurrences %v, want %d", len(got), got, len(tt.want))
+			}
+			for i, o := range got {
+				want := wallClock(t, tt.loc, tt.want[i])
+				if !o.Start.Equal(want) {
+					t.Errorf("occurrence %d starts %v, want %v", i, o.Start, want)
+				}
+				if !o.End.Equal(want.Add(duration)) {
+					t.Errorf("occurrence %d ends %v, want %v", i, o.End, want.Add(duration))
+				}
+			}
+		})
+	}
+}
+
+func TestRecurrenceStartsWith(t *testing.T) {
+	amsterdam := mustLocation(t, "Europe/Amsterdam")
+
+	tests := []struct {
+		name    string
+		rule    string
+		loc     *time.Location
+		dtstart string
+		want    bool
+	}{
+		{"plain rule starts anywhere", "FREQ=DAILY", time.UTC, "2026-01-07 10:00", true},
+		{"saturday rule on a saturday", "FREQ=WEEKLY;BYDAY=SA", time.UTC, "2026-01-03 10:00", true},
+		{"saturday rule on a friday", "FREQ=WEEKLY;BYDAY=SA", time.UTC, "2026-01-02 10:00", false},
+		{"last friday on the last friday", "FREQ=MONTHLY;BYDAY=-1FR", time.UTC, "2026-01-30 10:00", true},
+		{"last friday on an earlier friday", "FREQ=MONTHLY;BYDAY=-1FR", time.UTC, "2026-01-23 10:00", false},
+		{"last day on the last day", "FREQ=MONTHLY;BYMONTHDAY=-1", amsterdam, "2026-02-28 20:00", true},
+		{"last day on another day", "FREQ=MONTHLY;BYMONTHDAY=-1", amsterdam, "2026-02-27 20:00", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := mustRecurrence(t, tt.rule)
+
+			if got := r.StartsWith(wallClock(t, tt.loc, tt.dtstart), tt.loc); got != tt.want {
+				t.Fatalf("StartsWith = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecurrenceIncludes(t *testing.T) {
+	amsterdam := mustLocation(t, "Europe/Amsterdam")
+
+	tests := []struct {
+		name    string
+		rule    string
+		loc     *time.Location
+		dtstart string
+		start   time.Time
+		want    bool
+	}{
+		{"dtstart itself", "FREQ=DAILY;INTERVAL=2", time.UTC, "2026-01-01 09:00", wallClock(t, time.UTC, "2026-01-01 09:00"), true},
+		{"a later run", "FREQ=DAILY;INTERVAL=2", time.UTC, "2026-01-01 09:00", wallClock(t, time.UTC, "2026-01-05 09:00"), true},
+		{"a day the interval skips", "FREQ=DAILY;INTERVAL=2", time.UTC, "2026-01-01 09:00", wallClock(t, time.UTC, "2026-01-02 09:00"), false},
+		{"the right day at the wrong time", "FREQ=DAILY;INTERVAL=2", time.UTC, "2026-01-01 09:00", wallClock(t, time.UTC, "2026-01-03 09:01"), false},
+		{"before dtstart", "FREQ=DAILY", time.UTC, "2026-01-01 09:00", wallClock(t, time.UTC, "2025-12-31 09:00"), false},
+		{"past COUNT", "FREQ=DAILY;COUNT=3", time.UTC, "2026-01-01 09:00", wallClock(t, time.UTC, "2026-01-04 09:00"), false},
+		{"last run within COUNT", "FREQ=DAILY;COUNT=3", time.UTC, "2026-01-01 09:00", wallClock(t, time.UTC, "2026-01-03 09:00"), true},
+		{"past UNTIL", "FREQ=DAILY;UNTIL=20260103", time.UTC, "2026-01-01 09:00", wallClock(t, time.UTC, "2026-01-04 09:00"), false},
+		{"after DST at the local time", "FREQ=DAILY", amsterdam, "2026-03-28 09:00", wallClock(t, amsterdam, "2026-03-30 09:00"), true},
+		// 24 jam setelah 28 Mar 09:00 CET adalah 30 Mar 10:00 CEST, bukan jadwalnya
+		{"after DST at the old UTC offset", "FREQ=DAILY", amsterdam, "2026-03-28 09:00", wallClock(t, amsterdam, "2026-03-28 09:00").Add(48 * time.Hour), false},
+		{"negative BYDAY run", "FREQ=MONTHLY;BYDAY=-1FR", time.UTC, "2026-01-30 18:00", wallClock(t, time.UTC, "2026-02-27 18:00"), true},
+		{"negative BYDAY non-run", "FREQ=MONTHLY;BYDAY=-1FR", time.UTC, "2026-01-30 18:00", wallClock(t, time.UTC, "2026-02-20 18:00"), false},
+		{"same instant in another zone", "FREQ=WEEKLY", amsterdam, "2026-01-06 19:00", wallClock(t, time.UTC, "2026-01-13 18:00"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := mustRecurrence(t, tt.rule)
+
+			if got := r.Includes(wallClock(t, tt.loc, tt.dtstart), tt.loc, tt.start); got != tt.want {
+				t.Fatalf("Includes(%v) = %v, want %v", tt.start, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// StartJob runs fn every interval in the background, starting right away.
+// Each run gets its own timeout of one interval.
+func StartJob(name string, interval time.Duration, fn func(ctx context.Context) error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			if err := fn(ctx); err != nil {
+				log.Printf("Job %s failed: %v\n", name, err)
+			}
+			cancel()
+
+			<-ticker.C
+		}
+	}()
+
+	log.Printf("⏱️ Job %s scheduled every %s\n", name, interval)
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"time"
+)
+
+// SMTPMailer sends through an SMTP server. SMTP_TLS selects "implicit"
+// (SMTPS, usually port 465), "starttls" or "none"; it defaults by port.
+// With starttls a server that doesn't offer STARTTLS is refused; only
+// "none" sends in plaintext, e.g. to a local relay.
+type SMTPMailer struct {
+	host        string
+	port        string
+	username    string
+	password    string
+	from        string
+	implicitTLS bool
+	noTLS       bool
+}
+
+func NewSMTPMailerFromEnv(from string) (*SMTPMailer, error) {
+	m := &SMTPMailer{
+		host:     os.Getenv("SMTP_HOST"),
+		port:     os.Getenv("SMTP_PORT"),
+		username: os.Getenv("SMTP_USER"),
+		password: os.Getenv("SMTP_PASS"),
+		from:     from,
+	}
+
+	if m.host == "" || m.port == "" {
+		return nil, errors.New("SMTP_HOST and SMTP_PORT are required")
+	}
+
+	switch os.Getenv("SMTP_TLS") {
+	case "implicit":
+		m.implicitTLS = true
+	case "starttls":
+		m.implicitTLS = false
+	case "none":
+		m.noTLS = true
+	default:
+		m.implicitTLS = m.port == "465"
+	}
+
+	return m, nil
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg EmailMessage) error {
+	raw, err := buildMIMEMessage(m.from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	envelopeFrom, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return err
+	}
+
+	rcpt, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return err
+	}
+
+	client, err := m.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if m.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(envelopeFrom.Address); err != nil {
+		return err
+	}
+
+	if err := client.Rcpt(rcpt.Address); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(raw); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func (m *SMTPMailer) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(m.host, m.port)
+	tlsConfig := &tls.Config{ServerName: m.host}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+
+	if m.implicitTLS {
+		conn, err := (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+		return smtp.NewClient(conn, m.host)
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if m.noTLS {
+		return client, nil
+	}
+
+	if ok, _ := client.Extension("STARTTLS"); !ok {
+		client.Close()
+		return nil, errors.New("smtp server does not offer STARTTLS; set SMTP_TLS=none to send in plaintext")
+	}
+
+	if err := client.StartTLS(tlsConfig); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return client, nil
+}
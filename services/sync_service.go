@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"event-journal-backend/config"
+)
+
+const defaultSyncTombstoneRetention = 90 * 24 * time.Hour
+
+// SyncTombstoneRetention is how long deletions are kept for offline
+// clients. A client whose sync token is older gets a full download
+// instead. Override with SYNC_TOMBSTONE_RETENTION (e.g. "720h").
+func SyncTombstoneRetention() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("SYNC_TOMBSTONE_RETENTION")); err == nil && d > 0 {
+		return d
+	}
+	return defaultSyncTombstoneRetention
+}
+
+// StartSyncTombstoneCleaner purges expired sync tombstones once a day.
+func StartSyncTombstoneCleaner() {
+	StartJob("sync-tombstone-cleaner", 24*time.Hour, PurgeSyncTombstones)
+}
+
+func PurgeSyncTombstones(ctx context.Context) error {
+	result, err := config.DB.Exec(
+		ctx,
+		`DELETE FROM event_journal.sync_tombstones WHERE deleted_at < NOW() - make_interval(secs => $1)`,
+		SyncTombstoneRetention().Seconds(),
+	)
+	if err != nil {
+		return err
+	}
+
+	if n := result.RowsAffected(); n > 0 {
+		log.Printf("🧹 Purged %d sync tombstones\n", n)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	MaxTagsPerJournal = 10
+	maxTagLength      = 40
+)
+
+var (
+	ErrInvalidTag  = errors.New("invalid tag")
+	ErrTooManyTags = errors.New("too many tags")
+)
+
+// NormalizeTag turns "#Street Food" into "street-food". Letters, digits,
+// "-" and "_" are kept; spaces become "-".
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(tag), "#"))
+
+	var b strings.Builder
+	lastDash := false
+
+	for _, r := range strings.ToLower(tag) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			b.WriteRune(r)
+			lastDash = false
+		case r == '-' || unicode.IsSpace(r):
+			if !lastDash && b.Len() > 0 {
+				b.WriteRune('-')
+				lastDash = true
+			}
+		default:
+			return "", ErrInvalidTag
+		}
+	}
+
+	slug := strings.TrimRight(b.String(), "-")
+	if slug == "" || utf8.RuneCountInString(slug) > maxTagLength {
+		return "", ErrInvalidTag
+	}
+
+	return slug, nil
+}
+
+// NormalizeTags normalizes and dedupes tags, keeping their order.
+func NormalizeTags(tags []string) ([]string, error) {
+	seen := map[string]bool{}
+	slugs := []string{}
+
+	for _, tag := range tags {
+		slug, err := NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		if seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		slugs = append(slugs, slug)
+	}
+
+	if len(slugs) > MaxTagsPerJournal {
+		return nil, ErrTooManyTags
+	}
+
+	return slugs, nil
+}
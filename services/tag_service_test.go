@@ -0,0 +1,81 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	tests := []struct {
+		tag     string
+		want    string
+		wantErr bool
+	}{
+		{tag: "#Street Food", want: "street-food"},
+		{tag: "  ##kuliner  ", want: "kuliner"},
+		{tag: "street_food", want: "street_food"},
+		{tag: "Street -  Food", want: "street-food"},
+		{tag: "festival-2026-", want: "festival-2026"},
+		{tag: "- leading", want: "leading"},
+		{tag: "Kuliner Malam", want: "kuliner-malam"},
+		{tag: "Café", want: "café"},
+		{tag: "日本", want: "日本"},
+		{tag: strings.Repeat("a", maxTagLength), want: strings.Repeat("a", maxTagLength)},
+
+		{tag: "", wantErr: true},
+		{tag: "#", wantErr: true},
+		{tag: " - ", wantErr: true},
+		{tag: "rock&roll", wantErr: true},
+		{tag: "a.b", wantErr: true},
+		{tag: "emoji🎉", wantErr: true},
+		{tag: strings.Repeat("a", maxTagLength+1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			got, err := NormalizeTag(tt.tag)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidTag) {
+					t.Fatalf("NormalizeTag(%q) = %q, %v, want ErrInvalidTag", tt.tag, got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("NormalizeTag(%q) = %q, %v, want %q", tt.tag, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	got, err := NormalizeTags([]string{"#Street Food", "kuliner", "street-food", "Kuliner"})
+	if err != nil {
+		t.Fatalf("NormalizeTags: %v", err)
+	}
+	if want := []string{"street-food", "kuliner"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("NormalizeTags = %v, want %v", got, want)
+	}
+
+	if _, err := NormalizeTags([]string{"ok", "not ok!"}); !errors.Is(err, ErrInvalidTag) {
+		t.Fatalf("NormalizeTags with an invalid tag: %v, want ErrInvalidTag", err)
+	}
+
+	tooMany := make([]string, MaxTagsPerJournal+1)
+	for i := range tooMany {
+		tooMany[i] = "tag" + strings.Repeat("x", i)
+	}
+	if _, err := NormalizeTags(tooMany); !errors.Is(err, ErrTooManyTags) {
+		t.Fatalf("NormalizeTags with %d tags: %v, want ErrTooManyTags", len(tooMany), err)
+	}
+
+	// duplikat tidak dihitung dua kali
+	repeated := make([]string, MaxTagsPerJournal+5)
+	for i := range repeated {
+		repeated[i] = "same"
+	}
+	if got, err := NormalizeTags(repeated); err != nil || len(got) != 1 {
+		t.Fatalf("NormalizeTags with repeated tags = %v, %v, want one tag", got, err)
+	}
+}
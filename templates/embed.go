@@ -0,0 +1,9 @@
+package templates
+
+import "embed"
+
+// Emails holds the email layouts and pages so they are parsed once at
+// startup instead of being read from disk on every send.
+//
+//go:embed emails/*.html emails/*.txt emails/locales/*.json
+var Emails embed.FS